@@ -1,7 +1,7 @@
 package mygolox
 
 type Expr interface {
-	Accept(visitor VisitorExpr) any
+	Accept(visitor VisitorExpr) (any, error)
 }
 
 type Assign struct {
@@ -16,7 +16,7 @@ func NewAssign(Name Token, Value Expr) *Assign {
 	}
 }
 
-func (e Assign) Accept(visitor VisitorExpr) any {
+func (e *Assign) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitAssignExpr(e)
 }
 
@@ -34,7 +34,7 @@ func NewBinary(Left Expr, Operator Token, Right Expr) *Binary {
 	}
 }
 
-func (e Binary) Accept(visitor VisitorExpr) any {
+func (e *Binary) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitBinaryExpr(e)
 }
 
@@ -42,20 +42,78 @@ type Call struct {
 	Callee    Expr
 	Paren     Token
 	Arguments []Expr
+	Optional  bool
 }
 
-func NewCall(Callee Expr, Paren Token, Arguments []Expr) *Call {
+func NewCall(Callee Expr, Paren Token, Arguments []Expr, Optional bool) *Call {
 	return &Call{
 		Callee:    Callee,
 		Paren:     Paren,
 		Arguments: Arguments,
+		Optional:  Optional,
 	}
 }
 
-func (e Call) Accept(visitor VisitorExpr) any {
+func (e *Call) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitCallExpr(e)
 }
 
+type CompoundAssign struct {
+	Target   Expr
+	Operator Token
+	Value    Expr
+	Postfix  bool
+}
+
+func NewCompoundAssign(Target Expr, Operator Token, Value Expr, Postfix bool) *CompoundAssign {
+	return &CompoundAssign{
+		Target:   Target,
+		Operator: Operator,
+		Value:    Value,
+		Postfix:  Postfix,
+	}
+}
+
+func (e *CompoundAssign) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitCompoundAssignExpr(e)
+}
+
+type Conditional struct {
+	Condition  Expr
+	ThenBranch Expr
+	ElseBranch Expr
+}
+
+func NewConditional(Condition Expr, ThenBranch Expr, ElseBranch Expr) *Conditional {
+	return &Conditional{
+		Condition:  Condition,
+		ThenBranch: ThenBranch,
+		ElseBranch: ElseBranch,
+	}
+}
+
+func (e *Conditional) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitConditionalExpr(e)
+}
+
+type Get struct {
+	Object   Expr
+	Name     Token
+	Optional bool
+}
+
+func NewGet(Object Expr, Name Token, Optional bool) *Get {
+	return &Get{
+		Object:   Object,
+		Name:     Name,
+		Optional: Optional,
+	}
+}
+
+func (e *Get) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitGetExpr(e)
+}
+
 type Grouping struct {
 	Expression Expr
 }
@@ -66,10 +124,70 @@ func NewGrouping(Expression Expr) *Grouping {
 	}
 }
 
-func (e Grouping) Accept(visitor VisitorExpr) any {
+func (e *Grouping) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitGroupingExpr(e)
 }
 
+type Subscript struct {
+	Object  Expr
+	Bracket Token
+	Index   Expr
+}
+
+func NewSubscript(Object Expr, Bracket Token, Index Expr) *Subscript {
+	return &Subscript{
+		Object:  Object,
+		Bracket: Bracket,
+		Index:   Index,
+	}
+}
+
+func (e *Subscript) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitSubscriptExpr(e)
+}
+
+type Interpolation struct {
+	Parts []Expr
+}
+
+func NewInterpolation(Parts []Expr) *Interpolation {
+	return &Interpolation{
+		Parts: Parts,
+	}
+}
+
+func (e *Interpolation) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitInterpolationExpr(e)
+}
+
+type Lambda struct {
+	Declaration *Function
+}
+
+func NewLambda(Declaration *Function) *Lambda {
+	return &Lambda{
+		Declaration: Declaration,
+	}
+}
+
+func (e *Lambda) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitLambdaExpr(e)
+}
+
+type List struct {
+	Elements []Expr
+}
+
+func NewList(Elements []Expr) *List {
+	return &List{
+		Elements: Elements,
+	}
+}
+
+func (e *List) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitListExpr(e)
+}
+
 type Literal struct {
 	Value any
 }
@@ -80,7 +198,7 @@ func NewLiteral(Value any) *Literal {
 	}
 }
 
-func (e Literal) Accept(visitor VisitorExpr) any {
+func (e *Literal) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitLiteralExpr(e)
 }
 
@@ -98,10 +216,108 @@ func NewLogical(Left Expr, Operator Token, Right Expr) *Logical {
 	}
 }
 
-func (e Logical) Accept(visitor VisitorExpr) any {
+func (e *Logical) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitLogicalExpr(e)
 }
 
+type Map struct {
+	Keys   []Expr
+	Values []Expr
+}
+
+func NewMap(Keys []Expr, Values []Expr) *Map {
+	return &Map{
+		Keys:   Keys,
+		Values: Values,
+	}
+}
+
+func (e *Map) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitMapExpr(e)
+}
+
+type OptionalChain struct {
+	Expression Expr
+}
+
+func NewOptionalChain(Expression Expr) *OptionalChain {
+	return &OptionalChain{
+		Expression: Expression,
+	}
+}
+
+func (e *OptionalChain) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitOptionalChainExpr(e)
+}
+
+type Set struct {
+	Object Expr
+	Name   Token
+	Value  Expr
+}
+
+func NewSet(Object Expr, Name Token, Value Expr) *Set {
+	return &Set{
+		Object: Object,
+		Name:   Name,
+		Value:  Value,
+	}
+}
+
+func (e *Set) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitSetExpr(e)
+}
+
+type SetSubscript struct {
+	Object  Expr
+	Bracket Token
+	Index   Expr
+	Value   Expr
+}
+
+func NewSetSubscript(Object Expr, Bracket Token, Index Expr, Value Expr) *SetSubscript {
+	return &SetSubscript{
+		Object:  Object,
+		Bracket: Bracket,
+		Index:   Index,
+		Value:   Value,
+	}
+}
+
+func (e *SetSubscript) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitSetSubscriptExpr(e)
+}
+
+type Super struct {
+	Keyword Token
+	Method  Token
+}
+
+func NewSuper(Keyword Token, Method Token) *Super {
+	return &Super{
+		Keyword: Keyword,
+		Method:  Method,
+	}
+}
+
+func (e *Super) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitSuperExpr(e)
+}
+
+type This struct {
+	Keyword Token
+}
+
+func NewThis(Keyword Token) *This {
+	return &This{
+		Keyword: Keyword,
+	}
+}
+
+func (e *This) Accept(visitor VisitorExpr) (any, error) {
+	return visitor.VisitThisExpr(e)
+}
+
 type Unary struct {
 	Operator Token
 	Right    Expr
@@ -114,7 +330,7 @@ func NewUnary(Operator Token, Right Expr) *Unary {
 	}
 }
 
-func (e Unary) Accept(visitor VisitorExpr) any {
+func (e *Unary) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitUnaryExpr(e)
 }
 
@@ -128,17 +344,30 @@ func NewVariable(Name Token) *Variable {
 	}
 }
 
-func (e Variable) Accept(visitor VisitorExpr) any {
+func (e *Variable) Accept(visitor VisitorExpr) (any, error) {
 	return visitor.VisitVariableExpr(e)
 }
 
 type VisitorExpr interface {
-	VisitAssignExpr(expr Assign) any
-	VisitBinaryExpr(expr Binary) any
-	VisitCallExpr(expr Call) any
-	VisitGroupingExpr(expr Grouping) any
-	VisitLiteralExpr(expr Literal) any
-	VisitLogicalExpr(expr Logical) any
-	VisitUnaryExpr(expr Unary) any
-	VisitVariableExpr(expr Variable) any
+	VisitAssignExpr(expr *Assign) (any, error)
+	VisitBinaryExpr(expr *Binary) (any, error)
+	VisitCallExpr(expr *Call) (any, error)
+	VisitCompoundAssignExpr(expr *CompoundAssign) (any, error)
+	VisitConditionalExpr(expr *Conditional) (any, error)
+	VisitGetExpr(expr *Get) (any, error)
+	VisitGroupingExpr(expr *Grouping) (any, error)
+	VisitSubscriptExpr(expr *Subscript) (any, error)
+	VisitInterpolationExpr(expr *Interpolation) (any, error)
+	VisitLambdaExpr(expr *Lambda) (any, error)
+	VisitListExpr(expr *List) (any, error)
+	VisitLiteralExpr(expr *Literal) (any, error)
+	VisitLogicalExpr(expr *Logical) (any, error)
+	VisitMapExpr(expr *Map) (any, error)
+	VisitOptionalChainExpr(expr *OptionalChain) (any, error)
+	VisitSetExpr(expr *Set) (any, error)
+	VisitSetSubscriptExpr(expr *SetSubscript) (any, error)
+	VisitSuperExpr(expr *Super) (any, error)
+	VisitThisExpr(expr *This) (any, error)
+	VisitUnaryExpr(expr *Unary) (any, error)
+	VisitVariableExpr(expr *Variable) (any, error)
 }
@@ -1,7 +1,7 @@
 package mygolox
 
 type Stmt interface {
-	Accept(visitor VisitorStmt) any
+	Accept(visitor VisitorStmt) Completion
 }
 
 type Block struct {
@@ -14,10 +14,56 @@ func NewBlock(Statements []Stmt) *Block {
 	}
 }
 
-func (e Block) Accept(visitor VisitorStmt) any {
+func (e *Block) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitBlockStmt(e)
 }
 
+type Break struct {
+	Keyword Token
+}
+
+func NewBreak(Keyword Token) *Break {
+	return &Break{
+		Keyword: Keyword,
+	}
+}
+
+func (e *Break) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitBreakStmt(e)
+}
+
+type Class struct {
+	Name       Token
+	Superclass *Variable
+	Methods    []*Function
+}
+
+func NewClass(Name Token, Superclass *Variable, Methods []*Function) *Class {
+	return &Class{
+		Name:       Name,
+		Superclass: Superclass,
+		Methods:    Methods,
+	}
+}
+
+func (e *Class) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitClassStmt(e)
+}
+
+type Continue struct {
+	Keyword Token
+}
+
+func NewContinue(Keyword Token) *Continue {
+	return &Continue{
+		Keyword: Keyword,
+	}
+}
+
+func (e *Continue) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitContinueStmt(e)
+}
+
 type Express struct {
 	Expression Expr
 }
@@ -28,25 +74,53 @@ func NewExpress(Expression Expr) *Express {
 	}
 }
 
-func (e Express) Accept(visitor VisitorStmt) any {
+func (e *Express) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitExpressStmt(e)
 }
 
+type ForIn struct {
+	Name     Token
+	In       Token
+	Iterable Expr
+	Body     Stmt
+}
+
+func NewForIn(Name Token, In Token, Iterable Expr, Body Stmt) *ForIn {
+	return &ForIn{
+		Name:     Name,
+		In:       In,
+		Iterable: Iterable,
+		Body:     Body,
+	}
+}
+
+func (e *ForIn) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitForInStmt(e)
+}
+
 type Function struct {
-	Name   Token
-	Params []Token
-	Body   []Stmt
+	Name      Token
+	Params    []Token
+	Defaults  []Expr
+	Rest      *Token
+	Body      []Stmt
+	Generator bool
+	Doc       string
 }
 
-func NewFunction(Name Token, Params []Token, Body []Stmt) *Function {
+func NewFunction(Name Token, Params []Token, Defaults []Expr, Rest *Token, Body []Stmt, Generator bool, Doc string) *Function {
 	return &Function{
-		Name:   Name,
-		Params: Params,
-		Body:   Body,
+		Name:      Name,
+		Params:    Params,
+		Defaults:  Defaults,
+		Rest:      Rest,
+		Body:      Body,
+		Generator: Generator,
+		Doc:       Doc,
 	}
 }
 
-func (e Function) Accept(visitor VisitorStmt) any {
+func (e *Function) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitFunctionStmt(e)
 }
 
@@ -64,10 +138,48 @@ func NewIf(Condition Expr, ThenBranch Stmt, ElseBranch Stmt) *If {
 	}
 }
 
-func (e If) Accept(visitor VisitorStmt) any {
+func (e *If) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitIfStmt(e)
 }
 
+type Import struct {
+	Keyword Token
+	Path    Token
+	Alias   *Token
+	Names   []Token
+}
+
+func NewImport(Keyword Token, Path Token, Alias *Token, Names []Token) *Import {
+	return &Import{
+		Keyword: Keyword,
+		Path:    Path,
+		Alias:   Alias,
+		Names:   Names,
+	}
+}
+
+func (e *Import) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitImportStmt(e)
+}
+
+type Match struct {
+	Keyword Token
+	Subject Expr
+	Arms    []*MatchArm
+}
+
+func NewMatch(Keyword Token, Subject Expr, Arms []*MatchArm) *Match {
+	return &Match{
+		Keyword: Keyword,
+		Subject: Subject,
+		Arms:    Arms,
+	}
+}
+
+func (e *Match) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitMatchStmt(e)
+}
+
 type Print struct {
 	Expression Expr
 }
@@ -78,7 +190,7 @@ func NewPrint(Expression Expr) *Print {
 	}
 }
 
-func (e Print) Accept(visitor VisitorStmt) any {
+func (e *Print) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitPrintStmt(e)
 }
 
@@ -94,49 +206,116 @@ func NewReturn(Keyword Token, Value Expr) *Return {
 	}
 }
 
-func (e Return) Accept(visitor VisitorStmt) any {
+func (e *Return) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitReturnStmt(e)
 }
 
+type Throw struct {
+	Keyword Token
+	Value   Expr
+}
+
+func NewThrow(Keyword Token, Value Expr) *Throw {
+	return &Throw{
+		Keyword: Keyword,
+		Value:   Value,
+	}
+}
+
+func (e *Throw) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitThrowStmt(e)
+}
+
+type Try struct {
+	Body        []Stmt
+	CatchParam  *Token
+	CatchBody   []Stmt
+	FinallyBody []Stmt
+}
+
+func NewTry(Body []Stmt, CatchParam *Token, CatchBody []Stmt, FinallyBody []Stmt) *Try {
+	return &Try{
+		Body:        Body,
+		CatchParam:  CatchParam,
+		CatchBody:   CatchBody,
+		FinallyBody: FinallyBody,
+	}
+}
+
+func (e *Try) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitTryStmt(e)
+}
+
 type While struct {
 	condition Expr
 	body      Stmt
+	increment Expr
 }
 
-func NewWhile(condition Expr, body Stmt) *While {
+func NewWhile(condition Expr, body Stmt, increment Expr) *While {
 	return &While{
 		condition: condition,
 		body:      body,
+		increment: increment,
 	}
 }
 
-func (e While) Accept(visitor VisitorStmt) any {
+func (e *While) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitWhileStmt(e)
 }
 
 type Var struct {
 	Name        Token
 	Initializer Expr
+	Constant    bool
+	Doc         string
 }
 
-func NewVar(Name Token, Initializer Expr) *Var {
+func NewVar(Name Token, Initializer Expr, Constant bool, Doc string) *Var {
 	return &Var{
 		Name:        Name,
 		Initializer: Initializer,
+		Constant:    Constant,
+		Doc:         Doc,
 	}
 }
 
-func (e Var) Accept(visitor VisitorStmt) any {
+func (e *Var) Accept(visitor VisitorStmt) Completion {
 	return visitor.VisitVarStmt(e)
 }
 
+type Yield struct {
+	Keyword Token
+	Value   Expr
+}
+
+func NewYield(Keyword Token, Value Expr) *Yield {
+	return &Yield{
+		Keyword: Keyword,
+		Value:   Value,
+	}
+}
+
+func (e *Yield) Accept(visitor VisitorStmt) Completion {
+	return visitor.VisitYieldStmt(e)
+}
+
 type VisitorStmt interface {
-	VisitBlockStmt(stmt Block) any
-	VisitExpressStmt(stmt Express) any
-	VisitFunctionStmt(stmt Function) any
-	VisitIfStmt(stmt If) any
-	VisitPrintStmt(stmt Print) any
-	VisitReturnStmt(stmt Return) any
-	VisitWhileStmt(stmt While) any
-	VisitVarStmt(stmt Var) any
+	VisitBlockStmt(stmt *Block) Completion
+	VisitBreakStmt(stmt *Break) Completion
+	VisitClassStmt(stmt *Class) Completion
+	VisitContinueStmt(stmt *Continue) Completion
+	VisitExpressStmt(stmt *Express) Completion
+	VisitForInStmt(stmt *ForIn) Completion
+	VisitFunctionStmt(stmt *Function) Completion
+	VisitIfStmt(stmt *If) Completion
+	VisitImportStmt(stmt *Import) Completion
+	VisitMatchStmt(stmt *Match) Completion
+	VisitPrintStmt(stmt *Print) Completion
+	VisitReturnStmt(stmt *Return) Completion
+	VisitThrowStmt(stmt *Throw) Completion
+	VisitTryStmt(stmt *Try) Completion
+	VisitWhileStmt(stmt *While) Completion
+	VisitVarStmt(stmt *Var) Completion
+	VisitYieldStmt(stmt *Yield) Completion
 }
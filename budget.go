@@ -0,0 +1,74 @@
+package mygolox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrStepBudgetExhausted は実行したステップ数がInterpreterのMaxStepsを超えたことを表す.
+var ErrStepBudgetExhausted = errors.New("step budget exhausted")
+
+// InterruptError は実行が外部から打ち切られたことを表す構造体.errorインターフェイスを満たす.
+// Causeはcontext.DeadlineExceeded,context.CanceledまたはErrStepBudgetExhaustedで,errors.Isで区別できる.
+// ランタイムエラーと違ってcatchでは捕まえられない.
+type InterruptError struct {
+	Cause error
+}
+
+func (e *InterruptError) Error() string {
+	switch {
+	case errors.Is(e.Cause, context.DeadlineExceeded):
+		return "Execution deadline exceeded."
+	case errors.Is(e.Cause, context.Canceled):
+		return "Execution canceled."
+	case errors.Is(e.Cause, ErrStepBudgetExhausted):
+		return "Step budget exhausted."
+	}
+	return "Execution interrupted: " + e.Cause.Error()
+}
+
+func (e *InterruptError) Unwrap() error {
+	return e.Cause
+}
+
+// executionBudget は1回のInterpretContextの実行で使える資源.
+// ジェネレータの本体を実行するgoroutineとも共有するので,ステップ数はatomicに数える.
+type executionBudget struct {
+	ctx      context.Context
+	maxSteps int64
+	steps    atomic.Int64
+	// interrupt は最初に返したInterruptError.一度打ち切ったら,finallyの中などで続けて実行しようとしても打ち切る.
+	interrupt atomic.Pointer[InterruptError]
+}
+
+func newExecutionBudget(ctx context.Context, maxSteps int) *executionBudget {
+	return &executionBudget{
+		ctx:      ctx,
+		maxSteps: int64(maxSteps),
+	}
+}
+
+// step は文の実行や関数の呼び出しを1ステップとして数え,実行を続けられない場合はInterruptErrorを返す.
+func (b *executionBudget) step() error {
+	if interrupt := b.interrupt.Load(); interrupt != nil {
+		return interrupt
+	}
+	steps := b.steps.Add(1)
+	if b.maxSteps > 0 && steps > b.maxSteps {
+		return b.interrupted(ErrStepBudgetExhausted)
+	}
+	// 時間のかかるステップが少しだけ続く場合でも打ち切れるように,contextの終了は毎ステップ確かめる.
+	select {
+	case <-b.ctx.Done():
+		return b.interrupted(b.ctx.Err())
+	default:
+		return nil
+	}
+}
+
+// interrupted はcauseで実行を打ち切ったことを記録して,最初に記録したInterruptErrorを返す.
+func (b *executionBudget) interrupted(cause error) *InterruptError {
+	b.interrupt.CompareAndSwap(nil, &InterruptError{Cause: cause})
+	return b.interrupt.Load()
+}
@@ -0,0 +1,150 @@
+package mygolox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// interpret はsourceを構文解析して,ctxで実行する.
+func interpret(t *testing.T, ctx context.Context, interpreter *Interpreter, source string) error {
+	t.Helper()
+	log := &DiagnosticLog{}
+	statements := NewParser(NewScanner(source, log).ScanTokens(), log).Parse()
+	if log.HadError() {
+		t.Fatalf("unexpected compile error in %q", source)
+	}
+	NewResolver(interpreter, log).ResolveStmts(statements)
+	if log.HadError() {
+		t.Fatalf("unexpected resolve error in %q", source)
+	}
+	return interpreter.InterpretContext(ctx, statements)
+}
+
+func newTestInterpreter() *Interpreter {
+	return NewInterpreter(&DiagnosticLog{})
+}
+
+// global はトップレベルの変数nameの値.
+func global(t *testing.T, interpreter *Interpreter, name string) any {
+	t.Helper()
+	value, err := interpreter.Globals.get(Token{Typ: IDENTIFIER, Lexeme: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestInterpretContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := interpret(t, ctx, newTestInterpreter(), "while (true) {}")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// 時間のかかるステップが256回より少ない場合でも,期限が過ぎた時点で打ち切られる.
+func TestInterpretContextDeadlineInFewSteps(t *testing.T) {
+	source := "var x = (1 << 500000) - 1; var y; for (var i in range(60)) y = x * x;"
+	interpreter := newTestInterpreter()
+	interpreter.MaxSteps = 255
+	if err := interpret(t, context.Background(), interpreter, source); err != nil {
+		t.Fatalf("script takes more steps than expected: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := interpret(t, ctx, newTestInterpreter(), source)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v after %v, want context.DeadlineExceeded", err, time.Since(start))
+	}
+}
+
+func TestInterpretContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err := interpret(t, ctx, newTestInterpreter(), "fun loop() { while (true) {} } loop();")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestInterpretContextCanceledInNative(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	err := interpret(t, ctx, newTestInterpreter(), "sleep(60);")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("sleep was not interrupted, took %v", elapsed)
+	}
+}
+
+func TestInterpretContextStepBudget(t *testing.T) {
+	interpreter := newTestInterpreter()
+	interpreter.MaxSteps = 1000
+	err := interpret(t, context.Background(), interpreter, "var i = 0; while (true) { i++; }")
+	if !errors.Is(err, ErrStepBudgetExhausted) {
+		t.Fatalf("got %v, want ErrStepBudgetExhausted", err)
+	}
+}
+
+func TestInterpretContextStepBudgetInGenerator(t *testing.T) {
+	interpreter := newTestInterpreter()
+	interpreter.MaxSteps = 1000
+	source := `
+fun forever() {
+  while (true) yield 1;
+}
+for (var n in forever()) {}
+`
+	err := interpret(t, context.Background(), interpreter, source)
+	if !errors.Is(err, ErrStepBudgetExhausted) {
+		t.Fatalf("got %v, want ErrStepBudgetExhausted", err)
+	}
+}
+
+// 打ち切りはcatchで捕まえられず,finallyの中のコードも実行されずに抜けてくる.
+func TestInterruptPassesThroughTryFinally(t *testing.T) {
+	interpreter := newTestInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	source := `
+var log = "";
+try {
+  try {
+    while (true) {}
+  } finally {
+    log += "inner finally;";
+  }
+} catch (e) {
+  log += "caught;";
+} finally {
+  log += "finally;";
+}
+log += "after;";
+`
+	err := interpret(t, ctx, interpreter, source)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if got := global(t, interpreter, "log"); got != "" {
+		t.Fatalf("got log %q, want %q", got, "")
+	}
+}
+
+func TestInterpretContextReusable(t *testing.T) {
+	interpreter := newTestInterpreter()
+	interpreter.MaxSteps = 1000
+	if err := interpret(t, context.Background(), interpreter, "while (true) {}"); !errors.Is(err, ErrStepBudgetExhausted) {
+		t.Fatalf("got %v, want ErrStepBudgetExhausted", err)
+	}
+	if err := interpret(t, context.Background(), interpreter, "var x = 1 + 2;"); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+}
@@ -0,0 +1,132 @@
+package mygolox
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CallFrame は呼び出し中の関数を表すコールスタックの要素.
+// ランタイムエラーのトレースバックにも使い,そのときLineはその関数の中でエラーまたは呼び出しが起きた行になる.
+type CallFrame struct {
+	Function string
+	// Script は関数が定義されたスクリプトのパス.ネイティブ関数では空になる.
+	Script string
+	Line   int
+	native bool
+}
+
+func (c CallFrame) String() string {
+	if c.native {
+		return fmt.Sprintf("at %s (native)", c.Function)
+	}
+	return fmt.Sprintf("at %s (%s:%d)", c.Function, scriptName(c.Script), c.Line)
+}
+
+// scriptName はトレースバックに表示するスクリプトの名前.REPLで入力したコードにはパスが無い.
+func scriptName(path string) string {
+	if path == "" {
+		return "<stdin>"
+	}
+	return filepath.Base(path)
+}
+
+// newCallFrame はcalleeを呼び出すときにコールスタックに積むフレームを作る.
+// scriptは呼び出し元のスクリプトで,定義されたスクリプトが分からない呼び出し先に使う.
+func newCallFrame(callee LoxCallable, script string) CallFrame {
+	switch v := callee.(type) {
+	case *LoxFunction:
+		return CallFrame{Function: v.name(), Script: v.script}
+	case *LoxClass:
+		if initializer := v.findMethod("init"); initializer != nil {
+			return CallFrame{Function: v.Name, Script: initializer.script}
+		}
+		return CallFrame{Function: v.Name, Script: script}
+	case interface{ name() string }:
+		return CallFrame{Function: v.name(), native: true}
+	}
+	return CallFrame{Function: "native", native: true}
+}
+
+// pushFrame はcalleeの呼び出しをコールスタックに積む.
+func (i *Interpreter) pushFrame(callee LoxCallable) {
+	i.frames = append(i.frames, newCallFrame(callee, i.ScriptPath))
+}
+
+// popFrame はコールスタックから呼び出しを取り除く.
+// 呼び出しがランタイムエラーかthrowで終わった場合は,取り除いたフレームをエラーのトレースバックに加える.
+// tokenは呼び出し元での呼び出し箇所.
+func (i *Interpreter) popFrame(token Token, err error) {
+	frame := i.frames[len(i.frames)-1]
+	i.frames = i.frames[:len(i.frames)-1]
+	unwind(err, frame, token.Line)
+}
+
+// traceback はエラーが起きた関数から,トップレベルのコードまでの呼び出しのトレースバック.
+// RuntimeErrorとThrowErrorに埋め込み,エラーが呼び出しを抜けるたびに1フレームずつ加える.
+type traceback struct {
+	Trace []CallFrame
+	// line は次にTraceに加えるフレームの中で,エラーまたは呼び出しが起きた行.
+	line int
+	// scriptPath はエラーのTokenがあるスクリプト.located がfalseの間は,最初に抜けたネイティブ関数でないフレームのものにする.
+	scriptPath string
+	located    bool
+}
+
+// tracedError はトレースバックを持つエラー.
+type tracedError interface {
+	error
+	unwind(frame CallFrame, callLine int)
+	resumeAt(line int)
+	script() string
+}
+
+// unwind はerrがトレースバックを持つエラーであれば,エラーが抜けたframeを加える.
+func unwind(err error, frame CallFrame, callLine int) {
+	if traced, ok := err.(tracedError); ok {
+		traced.unwind(frame, callLine)
+	}
+}
+
+// unwind はエラーが抜けたframeをトレースバックに加える.callLineはframeを呼び出した箇所の行.
+func (t *traceback) unwind(frame CallFrame, callLine int) {
+	if !frame.native {
+		frame.Line = t.line
+		if !t.located {
+			t.scriptPath, t.located = frame.Script, true
+		}
+	}
+	t.Trace = append(t.Trace, frame)
+	t.line = callLine
+}
+
+// script はエラーのTokenがあるスクリプトのパス.
+func (t *traceback) script() string {
+	return t.scriptPath
+}
+
+// resumeAt は次に加えるフレームの中でエラーが起きた行をlineにする.
+func (t *traceback) resumeAt(line int) {
+	t.line = line
+}
+
+// String はトレースバックをフレームごとの行にする.再帰で同じフレームが続く場合は1つにまとめる.
+func (t *traceback) String() string {
+	var builder strings.Builder
+	repeated := 0
+	for i, frame := range t.Trace {
+		if i > 0 && frame == t.Trace[i-1] {
+			repeated++
+			continue
+		}
+		if repeated > 0 {
+			fmt.Fprintf(&builder, "\n    [previous frame repeated %d more times]", repeated)
+			repeated = 0
+		}
+		builder.WriteString("\n    " + frame.String())
+	}
+	if repeated > 0 {
+		fmt.Fprintf(&builder, "\n    [previous frame repeated %d more times]", repeated)
+	}
+	return builder.String()
+}
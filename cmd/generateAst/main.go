@@ -17,35 +17,58 @@ func main() {
 		os.Exit(64)
 	}
 	outputDir := flag.Arg(0)
-	err := defineAst(outputDir, "Expr", []string{
+	// 式の評価は値とエラーを,文の実行はCompletionを返す.
+	err := defineAst(outputDir, "Expr", "(any, error)", []string{
 		"Assign     : Name Token, Value Expr",
 		"Binary     : Left Expr, Operator Token, Right Expr",
-		"Call       : Callee Expr, Paren Token, Arguments []Expr",
+		"Call       : Callee Expr, Paren Token, Arguments []Expr, Optional bool",
+		"CompoundAssign : Target Expr, Operator Token, Value Expr, Postfix bool",
+		"Conditional : Condition Expr, ThenBranch Expr, ElseBranch Expr",
+		"Get        : Object Expr, Name Token, Optional bool",
 		"Grouping   : Expression Expr",
+		"Subscript  : Object Expr, Bracket Token, Index Expr",
+		"Interpolation : Parts []Expr",
+		"Lambda     : Declaration *Function",
+		"List       : Elements []Expr",
 		"Literal    : Value any",
 		"Logical    : Left Expr, Operator Token, Right Expr",
+		"Map        : Keys []Expr, Values []Expr",
+		"OptionalChain : Expression Expr",
+		"Set        : Object Expr, Name Token, Value Expr",
+		"SetSubscript : Object Expr, Bracket Token, Index Expr, Value Expr",
+		"Super      : Keyword Token, Method Token",
+		"This       : Keyword Token",
 		"Unary      : Operator Token, Right Expr",
 		"Variable   : Name Token",
 	})
 	if err != nil {
 		log.Fatalln(err)
 	}
-	err = defineAst(outputDir, "Stmt", []string{
+	err = defineAst(outputDir, "Stmt", "Completion", []string{
 		"Block      : Statements []Stmt",
+		"Break      : Keyword Token",
+		"Class      : Name Token, Superclass *Variable, Methods []*Function",
+		"Continue   : Keyword Token",
 		"Express    : Expression Expr",
-		"Function   : Name Token, Params []Token, Body []Stmt",
+		"ForIn      : Name Token, In Token, Iterable Expr, Body Stmt",
+		"Function   : Name Token, Params []Token, Defaults []Expr, Rest *Token, Body []Stmt, Generator bool, Doc string",
 		"If         : Condition Expr, ThenBranch Stmt, ElseBranch Stmt",
+		"Import     : Keyword Token, Path Token, Alias *Token, Names []Token",
+		"Match      : Keyword Token, Subject Expr, Arms []*MatchArm",
 		"Print      : Expression Expr",
 		"Return     : Keyword Token, Value Expr",
-		"While      : condition Expr, body Stmt",
-		"Var        : Name Token, Initializer Expr",
+		"Throw      : Keyword Token, Value Expr",
+		"Try        : Body []Stmt, CatchParam *Token, CatchBody []Stmt, FinallyBody []Stmt",
+		"While      : condition Expr, body Stmt, increment Expr",
+		"Var        : Name Token, Initializer Expr, Constant bool, Doc string",
+		"Yield      : Keyword Token, Value Expr",
 	})
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
-func defineAst(outputDir, baseName string, types []string) (err error) {
+func defineAst(outputDir, baseName, returnType string, types []string) (err error) {
 	path := filepath.Join(outputDir, baseName+".go")
 	writer, err := os.Create(path)
 	if err != nil {
@@ -62,7 +85,7 @@ func defineAst(outputDir, baseName string, types []string) (err error) {
 
 	// define baseName interface
 	fmt.Fprintln(writer, "type", baseName, "interface {")
-	fmt.Fprintln(writer, "	Accept(visitor Visitor"+baseName+") any")
+	fmt.Fprintln(writer, "	Accept(visitor Visitor"+baseName+")", returnType)
 	fmt.Fprintln(writer, "}")
 	fmt.Fprintln(writer)
 
@@ -71,15 +94,15 @@ func defineAst(outputDir, baseName string, types []string) (err error) {
 		typSplit := strings.Split(typ, ":")
 		structName := strings.TrimSpace(typSplit[0])
 		fields := strings.TrimSpace(typSplit[1])
-		defineType(writer, baseName, structName, fields)
+		defineType(writer, baseName, returnType, structName, fields)
 	}
 
-	defineVisitor(writer, baseName, types)
+	defineVisitor(writer, baseName, returnType, types)
 
 	return
 }
 
-func defineType(writer io.Writer, baseName, structName, fieldList string) {
+func defineType(writer io.Writer, baseName, returnType, structName, fieldList string) {
 	// define struct
 	fmt.Fprintln(writer, "type", structName, "struct {")
 	fields := strings.Split(fieldList, ", ")
@@ -101,19 +124,19 @@ func defineType(writer io.Writer, baseName, structName, fieldList string) {
 	fmt.Fprintln(writer)
 
 	// define accept
-	fmt.Fprintln(writer, "func (e", structName+")", "Accept(visitor Visitor"+baseName+")", "any", "{")
+	fmt.Fprintln(writer, "func (e", "*"+structName+")", "Accept(visitor Visitor"+baseName+")", returnType, "{")
 	fmt.Fprintln(writer, "	return visitor.Visit"+structName+baseName+"(e)")
 	fmt.Fprintln(writer, "}")
 
 	fmt.Fprintln(writer)
 }
 
-func defineVisitor(writer io.Writer, baseName string, types []string) {
+func defineVisitor(writer io.Writer, baseName, returnType string, types []string) {
 	fmt.Fprintln(writer, "type", "Visitor"+baseName, "interface {")
 	for _, typ := range types {
 		splitedTyp := strings.Split(typ, ":")[0]
 		typeName := strings.TrimSpace(splitedTyp)
-		fmt.Fprintln(writer, "	Visit"+typeName+baseName+"("+strings.ToLower(baseName), typeName+")", "any")
+		fmt.Fprintln(writer, "	Visit"+typeName+baseName+"("+strings.ToLower(baseName), "*"+typeName+")", returnType)
 	}
 	fmt.Fprintln(writer, "}")
 	fmt.Fprintln(writer)
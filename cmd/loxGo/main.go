@@ -9,7 +9,10 @@ import (
 	"os"
 )
 
-var interpreter *mygolox.Interpreter = mygolox.NewInterpreter()
+// diagnostics はエラーを標準エラー出力に表示しながら,終了コードを決めるために集めておく.
+var diagnostics = &mygolox.DiagnosticLog{}
+var sink = mygolox.MultiSink{mygolox.NewWriterSink(os.Stderr), diagnostics}
+var interpreter *mygolox.Interpreter = mygolox.NewInterpreter(sink)
 
 func main() {
 	flag.Parse()
@@ -28,12 +31,14 @@ func runFile(path string) {
 	if err != nil {
 		log.Fatalln(err)
 	}
-	run(string(bytes))
+	interpreter.ScriptPath = path
+	run(string(bytes), path)
+	interpreter.Close()
 
-	if mygolox.HadError {
+	if diagnostics.HadError() {
 		os.Exit(65)
 	}
-	if mygolox.HadRuntimeError {
+	if diagnostics.HadRuntimeError() {
 		os.Exit(70)
 	}
 }
@@ -45,25 +50,28 @@ func runPrompt() {
 		if !reader.Scan() {
 			break
 		}
-		run(reader.Text())
-		mygolox.HadError = false
+		run(reader.Text(), "")
+		diagnostics.Reset()
 	}
+	interpreter.Close()
 }
 
-func run(source string) {
-	scan := mygolox.NewScanner(source)
+// run はsourceを実行する.scriptはsourceを読み込んだファイルのパスで,REPLの入力では空.
+func run(source string, script string) {
+	scriptSink := mygolox.NewScriptSink(sink, script)
+	scan := mygolox.NewScanner(source, scriptSink)
 	tokens := scan.ScanTokens()
-	parser := mygolox.NewParser(tokens)
+	parser := mygolox.NewParser(tokens, scriptSink)
 	statements := parser.Parse()
 
-	if mygolox.HadError {
+	if diagnostics.HadError() {
 		return
 	}
 
-	resolver := mygolox.NewResolver(interpreter)
+	resolver := mygolox.NewResolver(interpreter, scriptSink)
 	resolver.ResolveStmts(statements)
 
-	if mygolox.HadError {
+	if diagnostics.HadError() {
 		return
 	}
 
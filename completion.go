@@ -0,0 +1,40 @@
+package mygolox
+
+// CompletionKind は文の実行がどのように終わったかを表す.
+type CompletionKind int
+
+const (
+	// NORMAL_COMPLETION は次の文の実行に進む.
+	NORMAL_COMPLETION CompletionKind = iota
+	// RETURN_COMPLETION はreturn文で関数から戻る.Valueに戻り値が入る.
+	RETURN_COMPLETION
+	// BREAK_COMPLETION はbreak文でループを抜ける.
+	BREAK_COMPLETION
+	// CONTINUE_COMPLETION はcontinue文でループの次の反復に進む.
+	CONTINUE_COMPLETION
+	// ERROR_COMPLETION はランタイムエラーやthrowで実行を中断する.Errにエラーが入る.
+	ERROR_COMPLETION
+)
+
+// Completion は文を実行した結果.java実装のloxで例外を使っている制御の移動を値として表す.
+// ゼロ値は正常な完了を表す.
+type Completion struct {
+	Kind  CompletionKind
+	Value any
+	Err   error
+}
+
+// NewReturnCompletion はreturn文による完了のコンストラクタ.
+func NewReturnCompletion(value any) Completion {
+	return Completion{Kind: RETURN_COMPLETION, Value: value}
+}
+
+// NewErrorCompletion はエラーによる完了のコンストラクタ.
+func NewErrorCompletion(err error) Completion {
+	return Completion{Kind: ERROR_COMPLETION, Err: err}
+}
+
+// isNormal は次の文の実行に進むかどうかを返す.
+func (c Completion) isNormal() bool {
+	return c.Kind == NORMAL_COMPLETION
+}
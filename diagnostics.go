@@ -0,0 +1,179 @@
+package mygolox
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// Phase はエラーが見つかった処理の段階.
+type Phase int
+
+const (
+	SCAN_PHASE Phase = iota
+	PARSE_PHASE
+	RESOLVE_PHASE
+	RUNTIME_PHASE
+)
+
+func (p Phase) String() string {
+	switch p {
+	case SCAN_PHASE:
+		return "scan"
+	case PARSE_PHASE:
+		return "parse"
+	case RESOLVE_PHASE:
+		return "resolve"
+	case RUNTIME_PHASE:
+		return "runtime"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// Diagnostic は報告されたエラー1つ分の情報.
+type Diagnostic struct {
+	Phase Phase
+	// Script はエラーが見つかったスクリプトのパス.importしたモジュールの中のエラーではモジュールのファイルになる.
+	// REPLの入力のようにファイルが無い場合は空.
+	Script string
+	// Line はエラーの場所の行.実行の打ち切りのように場所が無いエラーでは0になる.
+	Line int
+	// Column は行の中の位置(1から数える).分からない場合は0.
+	Column int
+	// Where はエラーの場所の説明(ex: " at 'foo'", " at end").
+	Where   string
+	Message string
+	// Err は実行時のエラーそのもの.トレースバックなどはここから取り出す.
+	Err error
+}
+
+// newTokenDiagnostic はtokenの場所で見つかった構文解析または変数解決のエラーを作る.
+func newTokenDiagnostic(phase Phase, token *Token, message string) Diagnostic {
+	where := " at '" + token.Lexeme + "'"
+	if token.Typ == EOF {
+		where = " at end"
+	}
+	return Diagnostic{Phase: phase, Line: token.Line, Column: token.Column, Where: where, Message: message}
+}
+
+// newRuntimeDiagnostic はトップレベルまで抜けてきた実行時のエラーを作る.
+func newRuntimeDiagnostic(err error) Diagnostic {
+	diagnostic := Diagnostic{Phase: RUNTIME_PHASE, Message: err.Error(), Err: err}
+	if traced, ok := err.(tracedError); ok {
+		diagnostic.Script = traced.script()
+	}
+	switch err := err.(type) {
+	case *RuntimeError:
+		diagnostic.Line, diagnostic.Column, diagnostic.Message = err.Token.Line, err.Token.Column, err.Message
+	case *ThrowError:
+		diagnostic.Line, diagnostic.Column = err.Token.Line, err.Token.Column
+	}
+	return diagnostic
+}
+
+// DiagnosticSink はScanner,Parser,ResolverおよびInterpreterが見つけたエラーを受け取るインターフェイス.
+type DiagnosticSink interface {
+	Report(diagnostic Diagnostic)
+}
+
+// WriterSink はエラーを1つずつWriterに書き出すDiagnosticSink.
+type WriterSink struct {
+	Writer io.Writer
+}
+
+// NewWriterSink はWriterSinkのコンストラクタ.
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{
+		Writer: writer,
+	}
+}
+
+func (w *WriterSink) Report(diagnostic Diagnostic) {
+	if diagnostic.Phase == RUNTIME_PHASE {
+		fmt.Fprintln(w.Writer, diagnostic.Err)
+		return
+	}
+	if diagnostic.Script != "" {
+		fmt.Fprintln(w.Writer, "[line", diagnostic.Line, "in", filepath.Base(diagnostic.Script), "] Error", diagnostic.Where, ":", diagnostic.Message)
+		return
+	}
+	fmt.Fprintln(w.Writer, "[line", diagnostic.Line, "] Error", diagnostic.Where, ":", diagnostic.Message)
+}
+
+// DiagnosticLog は報告されたエラーを集めておくDiagnosticSink.
+type DiagnosticLog struct {
+	Diagnostics []Diagnostic
+}
+
+func (l *DiagnosticLog) Report(diagnostic Diagnostic) {
+	l.Diagnostics = append(l.Diagnostics, diagnostic)
+}
+
+// HadError は字句解析,構文解析または変数解決の処理でエラーがあったかどうか.
+func (l *DiagnosticLog) HadError() bool {
+	for _, diagnostic := range l.Diagnostics {
+		if diagnostic.Phase != RUNTIME_PHASE {
+			return true
+		}
+	}
+	return false
+}
+
+// HadRuntimeError はコードを実行する際の処理でエラーがあったかどうか.
+func (l *DiagnosticLog) HadRuntimeError() bool {
+	for _, diagnostic := range l.Diagnostics {
+		if diagnostic.Phase == RUNTIME_PHASE {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset は集めたエラーを捨てる.
+func (l *DiagnosticLog) Reset() {
+	l.Diagnostics = nil
+}
+
+// MultiSink は同じエラーを複数のDiagnosticSinkに報告する.
+type MultiSink []DiagnosticSink
+
+func (m MultiSink) Report(diagnostic Diagnostic) {
+	for _, sink := range m {
+		sink.Report(diagnostic)
+	}
+}
+
+// ScriptSink はScriptが分からないエラーにScriptを添えて,Sinkに報告するDiagnosticSink.
+// ScannerやParserはスクリプトのパスを知らないので,これを通して報告する.
+type ScriptSink struct {
+	Sink   DiagnosticSink
+	Script string
+}
+
+// NewScriptSink はScriptSinkのコンストラクタ.
+func NewScriptSink(sink DiagnosticSink, script string) *ScriptSink {
+	return &ScriptSink{
+		Sink:   sink,
+		Script: script,
+	}
+}
+
+func (s *ScriptSink) Report(diagnostic Diagnostic) {
+	if diagnostic.Script == "" {
+		diagnostic.Script = s.Script
+	}
+	s.Sink.Report(diagnostic)
+}
+
+// discardSink は報告されたエラーを捨てるDiagnosticSink.
+type discardSink struct{}
+
+func (discardSink) Report(diagnostic Diagnostic) {}
+
+// orDiscard はsinkがnilであればエラーを捨てるDiagnosticSinkを返す.
+func orDiscard(sink DiagnosticSink) DiagnosticSink {
+	if sink == nil {
+		return discardSink{}
+	}
+	return sink
+}
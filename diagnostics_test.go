@@ -0,0 +1,142 @@
+package mygolox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run はpathのスクリプトを実行して,報告されたエラーを返す.
+func run(t *testing.T, path string) []Diagnostic {
+	t.Helper()
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &DiagnosticLog{}
+	sink := NewScriptSink(log, path)
+	interpreter := NewInterpreter(log)
+	interpreter.ScriptPath = path
+	statements := NewParser(NewScanner(string(source), sink).ScanTokens(), sink).Parse()
+	if log.HadError() {
+		return log.Diagnostics
+	}
+	NewResolver(interpreter, sink).ResolveStmts(statements)
+	if log.HadError() {
+		return log.Diagnostics
+	}
+	interpreter.Interpret(statements)
+	return log.Diagnostics
+}
+
+// writeScripts はdirにファイル名と内容の組のスクリプトを書き出す.
+func writeScripts(t *testing.T, dir string, scripts map[string]string) {
+	t.Helper()
+	for name, source := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiagnosticColumn(t *testing.T) {
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"main.lox": "var a = 1;\n  var b = @;\n  print a +;\n",
+	})
+	main := filepath.Join(dir, "main.lox")
+	diagnostics := run(t, main)
+	want := []struct {
+		phase        Phase
+		line, column int
+	}{
+		{SCAN_PHASE, 2, 11},
+		{PARSE_PHASE, 2, 12},
+		{PARSE_PHASE, 3, 12},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics %v, want %d", len(diagnostics), diagnostics, len(want))
+	}
+	for i, w := range want {
+		d := diagnostics[i]
+		if d.Phase != w.phase || d.Line != w.line || d.Column != w.column || d.Script != main {
+			t.Errorf("diagnostic %d: got %v %s:%d:%d, want %v %s:%d:%d", i, d.Phase, d.Script, d.Line, d.Column, w.phase, main, w.line, w.column)
+		}
+	}
+}
+
+func TestDiagnosticScriptOfModule(t *testing.T) {
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"bad.lox":     "var x = ;\n",
+		"failing.lox": "fun f() {\n  return 1 + nil;\n}\n",
+		"compile.lox": "import \"bad.lox\";\n",
+		"runtime.lox": "import { f } from \"failing.lox\";\nf();\n",
+	})
+
+	diagnostics := run(t, filepath.Join(dir, "compile.lox"))
+	if len(diagnostics) != 2 {
+		t.Fatalf("got %d diagnostics %v, want 2", len(diagnostics), diagnostics)
+	}
+	if d := diagnostics[0]; d.Phase != PARSE_PHASE || d.Script != filepath.Join(dir, "bad.lox") || d.Line != 1 {
+		t.Errorf("got %v %s:%d, want parse error in bad.lox:1", d.Phase, d.Script, d.Line)
+	}
+	if d := diagnostics[1]; d.Phase != RUNTIME_PHASE || d.Script != filepath.Join(dir, "compile.lox") || d.Line != 1 {
+		t.Errorf("got %v %s:%d, want runtime error in compile.lox:1", d.Phase, d.Script, d.Line)
+	}
+
+	diagnostics = run(t, filepath.Join(dir, "runtime.lox"))
+	if len(diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics %v, want 1", len(diagnostics), diagnostics)
+	}
+	if d := diagnostics[0]; d.Script != filepath.Join(dir, "failing.lox") || d.Line != 2 || d.Column != 12 {
+		t.Errorf("got %s:%d:%d, want failing.lox:2:12", d.Script, d.Line, d.Column)
+	}
+}
+
+// エラーの報告先がnilでも,エラーは捨てられるだけでpanicしない.
+func TestNilDiagnosticSink(t *testing.T) {
+	NewParser(NewScanner("var x = @;", nil).ScanTokens(), nil).Parse()
+	statements := NewParser(NewScanner("return; var y = nil + 1;", nil).ScanTokens(), nil).Parse()
+	interpreter := NewInterpreter(nil)
+	NewResolver(interpreter, nil).ResolveStmts(statements)
+	if err := interpreter.InterpretContext(context.Background(), statements[1:]); err == nil {
+		t.Fatal("got nil, want runtime error")
+	}
+}
+
+// importした関数の中で作られた関数は,呼び出し元ではなくモジュールのスクリプトで定義されたものとして扱う.
+func TestScriptOfClosureFromModule(t *testing.T) {
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"m.lox":         "fun make() {\n  return fun () {\n    throw \"bad\";\n  };\n}\nfun gen() {\n  var inner = fun () { return nil + 1; };\n  yield inner();\n}\n",
+		"lambda.lox":    "import { make } from \"m.lox\";\nvar f = make();\nf();\n",
+		"generator.lox": "import { gen } from \"m.lox\";\nvar g = gen();\ng.next();\n",
+	})
+	tests := []struct {
+		main  string
+		line  int
+		trace []string
+	}{
+		{"lambda.lox", 3, []string{"at anonymous (m.lox:3)", "at <script> (lambda.lox:3)"}},
+		{"generator.lox", 7, []string{"at anonymous (m.lox:7)", "at gen (m.lox:8)", "at next (native)", "at <script> (generator.lox:3)"}},
+	}
+	for _, test := range tests {
+		diagnostics := run(t, filepath.Join(dir, test.main))
+		if len(diagnostics) != 1 {
+			t.Fatalf("%s: got %d diagnostics %v, want 1", test.main, len(diagnostics), diagnostics)
+		}
+		d := diagnostics[0]
+		if d.Script != filepath.Join(dir, "m.lox") || d.Line != test.line {
+			t.Errorf("%s: got %s:%d, want m.lox:%d", test.main, d.Script, d.Line, test.line)
+		}
+		message := d.Err.Error()
+		for _, frame := range test.trace {
+			if !strings.Contains(message, "\n    "+frame) {
+				t.Errorf("%s: traceback %q does not contain %q", test.main, message, frame)
+			}
+		}
+	}
+}
@@ -4,6 +4,8 @@ package mygolox
 type Environment struct {
 	Enclosing *Environment
 	Values    map[string]any
+	// constants はconstで定義された名前.必要になるまでnilのままにしておく.
+	constants map[string]bool
 }
 
 // NewEnvironment はEnvironmentのコンストラクタ.
@@ -26,6 +28,27 @@ func (e *Environment) define(name string, value any) {
 	e.Values[name] = value
 }
 
+// declare は宣言文でnameを定義する.同じ環境でconstで定義された名前は宣言し直せない.
+func (e *Environment) declare(name Token, value any) error {
+	if e.constants[name.Lexeme] {
+		return NewRuntimeError(name, "Can't redeclare constant '"+name.Lexeme+"'.")
+	}
+	e.Values[name.Lexeme] = value
+	return nil
+}
+
+// declareConstant はconst宣言でnameを再代入できない名前として定義する.
+func (e *Environment) declareConstant(name Token, value any) error {
+	if err := e.declare(name, value); err != nil {
+		return err
+	}
+	if e.constants == nil {
+		e.constants = map[string]bool{}
+	}
+	e.constants[name.Lexeme] = true
+	return nil
+}
+
 func (e *Environment) ancestor(distance int) *Environment {
 	environment := e
 	for i := 0; i < distance; i++ {
@@ -57,6 +80,9 @@ func (e *Environment) get(name Token) (any, error) {
 
 func (e *Environment) assign(name Token, value any) error {
 	if _, ok := e.Values[name.Lexeme]; ok {
+		if e.constants[name.Lexeme] {
+			return NewRuntimeError(name, "Can't assign to constant '"+name.Lexeme+"'.")
+		}
 		e.Values[name.Lexeme] = value
 		return nil
 	}
@@ -1,47 +1,71 @@
 package mygolox
 
-import (
-	"fmt"
-	"os"
-)
+import "fmt"
 
-// HadError は字句解析,構文解析または変数解決の処理でエラーがあったことを伝えるフラグ.
-var HadError = false
+// RuntimeError はランタイムエラーを報告するための構造体.errorインターフェイスを満たす.
+type RuntimeError struct {
+	Token   Token
+	Message string
+	traceback
+}
 
-// HadRuntimeError はコードを実行する際の処理でエラーがあったことを伝えるフラグ.
-var HadRuntimeError = false
+// NewRuntimeError はRuntimeErrorのコンストラクタ.
+func NewRuntimeError(token Token, message string) *RuntimeError {
+	return &RuntimeError{
+		Token:     token,
+		Message:   message,
+		traceback: traceback{line: token.Line},
+	}
+}
 
-func scannerError(line int, message string) {
-	report(line, "", message)
+func (r *RuntimeError) Error() string {
+	return fmt.Sprintf("%s\n[line %d]%s", r.Message, r.Token.Line, r.traceback.String())
 }
 
-func parserResolverError(token *Token, message string) {
-	if token.Typ == EOF {
-		report(token.Line, " at end", message)
-	} else {
-		report(token.Line, " at '"+token.Lexeme+"'", message)
+// withLocation はネイティブ関数などが返した場所を持たないエラーを,tokenの場所のランタイムエラーにする.
+// 場所を持つエラーや,実行の中断を伝えるエラーはそのまま返す.
+func withLocation(token Token, err error) error {
+	switch err.(type) {
+	case nil, *RuntimeError, *ThrowError, *InterruptError, *GeneratorExit:
+		return err
 	}
+	return NewRuntimeError(token, err.Error())
 }
 
-func report(line int, where, message string) {
-	fmt.Fprintln(os.Stderr, "[line", line, "] Error", where, ":", message)
-	HadError = true
+// ThrowError はthrow文で投げられた値をcatchまで伝えるための構造体.errorインターフェイスを満たす.
+// トレースバックはthrow文の場所から数える.
+type ThrowError struct {
+	Token Token
+	Value any
+	traceback
 }
 
-// RuntimeError はランタイムエラーを報告するための構造体.errorインターフェイスを満たす.
-type RuntimeError struct {
-	Token   Token
-	Message string
+// NewThrowError はThrowErrorのコンストラクタ.
+func NewThrowError(token Token, value any) *ThrowError {
+	return &ThrowError{
+		Token:     token,
+		Value:     value,
+		traceback: traceback{line: token.Line},
+	}
 }
 
-// NewRuntimeError はRuntimeErrorのコンストラクタ.
-func NewRuntimeError(token Token, message string) *RuntimeError {
-	return &RuntimeError{
-		Token:   token,
-		Message: message,
+func (t *ThrowError) Error() string {
+	// catchしたランタイムエラーを投げ直した場合は元のエラーと同じ形式で表示する.
+	// 場所はトレースバックと合わせて,元のエラーの行ではなく投げ直したthrow文の行にする.
+	if instance, ok := t.Value.(*LoxInstance); ok && instance.klass == errorClass {
+		return fmt.Sprintf("%s\n[line %d]%s", stringify(instance.fields["message"]), t.Token.Line, t.traceback.String())
 	}
+	return fmt.Sprintf("Uncaught exception: %s\n[line %d]%s", stringify(t.Value), t.Token.Line, t.traceback.String())
 }
 
-func (r *RuntimeError) Error() string {
-	return fmt.Sprintf("%s\n[line %d]", r.Message, r.Token.Line)
+// errorClass はcatchしたランタイムエラーを表すインスタンスのクラス.
+var errorClass = NewLoxClass("Error", nil, map[string]*LoxFunction{})
+
+// newErrorValue はランタイムエラーをloxの値に変換する.
+// 変換した値はmessageとlineのフィールドを持つ.
+func newErrorValue(err *RuntimeError) *LoxInstance {
+	instance := NewLoxInstance(errorClass)
+	instance.set(*NewToken(IDENTIFIER, "message", nil, err.Token.Line), err.Message)
+	instance.set(*NewToken(IDENTIFIER, "line", nil, err.Token.Line), int64(err.Token.Line))
+	return instance
 }
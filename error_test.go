@@ -0,0 +1,39 @@
+package mygolox
+
+import (
+	"context"
+	"testing"
+)
+
+// catchしたランタイムエラーを投げ直すと,場所とトレースバックはどちらも投げ直したthrow文になる.
+func TestRethrownRuntimeErrorLocation(t *testing.T) {
+	source := `fun fail() {
+  nil();
+}
+fun rethrow() {
+  try {
+    fail();
+  } catch (e) {
+    throw e;
+  }
+}
+rethrow();
+`
+	log := &DiagnosticLog{}
+	interpreter := NewInterpreter(log)
+	interpreter.ScriptPath = "main.lox"
+	if err := interpret(t, context.Background(), interpreter, source); err == nil {
+		t.Fatal("got nil, want rethrown error")
+	}
+	if len(log.Diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(log.Diagnostics))
+	}
+	d := log.Diagnostics[0]
+	want := "Can only call functions and classes.\n[line 8]\n    at rethrow (main.lox:8)\n    at <script> (main.lox:11)"
+	if got := d.Err.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if d.Line != 8 || d.Script != "main.lox" {
+		t.Errorf("got %s:%d, want main.lox:8", d.Script, d.Line)
+	}
+}
@@ -1,165 +1,591 @@
 package mygolox
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"math"
+	"math/big"
+	"strings"
 )
 
 // Interpreter は構文木を解釈するための構造体.java実装のloxにおけるInterpreterクラス.
+// Globalsは実行中のモジュールのトップレベルの環境で,ネイティブ関数はその外側の環境に定義される.
+// 式の評価は値とエラーを返し,文の実行はCompletionを返す.
 type Interpreter struct {
 	Globals     *Environment
 	Environment *Environment
 	Locals      map[Expr]int
+	// Diagnostics は実行時のエラーの報告先.
+	Diagnostics DiagnosticSink
+	// ScriptPath は実行中のスクリプトのパス.importの相対パスはこのファイルのディレクトリを基準にする.
+	ScriptPath string
+	// MaxCallDepth は関数呼び出しの深さの上限.超える呼び出しは捕まえられるランタイムエラーになる.
+	// 0以下の場合は制限しないが,深い再帰はGoのスタックを使い切ってプロセスを終了させる.
+	MaxCallDepth int
+	// MaxSteps は1回のInterpretContextで実行できる文と関数呼び出しの数の上限.0以下の場合は制限しない.
+	MaxSteps int
+	builtins *Environment
+	modules  map[string]*LoxModule
+	loading  map[string]bool
+	// generator は実行中のジェネレータの本体の状態.ジェネレータの本体の外ではnil.
+	generator *generatorState
+	// generators は中断しているジェネレータの本体.ジェネレータの本体を実行するInterpreterの複製とも共有する.
+	generators *suspendedGenerators
+	// frames は呼び出し中の関数のコールスタック.トップレベルのコードのフレームは含まない.
+	frames []CallFrame
+	// budget は実行中のInterpretContextのcontextとステップ数.ジェネレータの本体ではgeneratorのものを使う.
+	budget *executionBudget
 }
 
+// DefaultMaxCallDepth はInterpreterのMaxCallDepthの既定値.
+const DefaultMaxCallDepth = 1000
+
 // NewInterpreter はInterpreterのコンストラクタ.
-func NewInterpreter() *Interpreter {
-	global := NewEnvironment()
-	global.define("clock", NewClock())
+// diagnosticsには実行時のエラーと,importしたモジュールの構文解析と変数解決のエラーが報告される.
+// diagnosticsがnilの場合,エラーは捨てられる.
+func NewInterpreter(diagnostics DiagnosticSink) *Interpreter {
+	builtins := NewEnvironment()
+	builtins.define("clock", NewClock())
+	builtins.define("sleep", NewSleep())
+	builtins.define("len", NewLen())
+	builtins.define("has", NewHas())
+	builtins.define("delete", NewDelete())
+	builtins.define("keys", NewKeys())
+	builtins.define("range", NewRange())
+	global := NewEnvironment().ChangeEnclosing(builtins)
 	return &Interpreter{
-		Globals:     global,
-		Environment: global,
-		Locals:      map[Expr]int{},
+		Globals:      global,
+		Environment:  global,
+		Locals:       map[Expr]int{},
+		Diagnostics:  orDiscard(diagnostics),
+		MaxCallDepth: DefaultMaxCallDepth,
+		builtins:     builtins,
+		budget:       newExecutionBudget(context.Background(), 0),
+		modules:      map[string]*LoxModule{},
+		loading:      map[string]bool{},
+		generators:   newSuspendedGenerators(),
 	}
 }
 
 // Interpret は構文木を実行するためのエントリーポイントとなるメソッド.
 func (i *Interpreter) Interpret(statements []Stmt) {
+	i.InterpretContext(context.Background(), statements)
+}
+
+// InterpretContext はctxが終了するか,ステップ数がMaxStepsを超えるまで構文木を実行する.
+// 実行を打ち切った場合は*InterruptErrorを,ランタイムエラーの場合はそのエラーを報告して返す.
+func (i *Interpreter) InterpretContext(ctx context.Context, statements []Stmt) error {
+	previousBudget := i.budget
+	defer func() {
+		i.budget = previousBudget
+	}()
+	i.budget = newExecutionBudget(ctx, i.MaxSteps)
+
+	if err := ctx.Err(); err != nil {
+		return i.reportRuntimeError(&InterruptError{Cause: err})
+	}
 	for _, statement := range statements {
-		err := i.execute(statement)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			HadRuntimeError = true
-			return
+		completion := i.execute(statement)
+		if completion.Kind == ERROR_COMPLETION {
+			return i.reportRuntimeError(completion.Err)
 		}
 	}
+	return nil
 }
 
-func (i *Interpreter) VisitBinaryExpr(expr Binary) any {
-	left := i.evaluate(expr.Left)
-	if v, ok := left.(error); ok {
-		return v
+// Close は中断したままのジェネレータの本体をfinallyも実行せずに終了させて,本体のgoroutineを解放する.
+// 以降,それらのジェネレータは終了したものとして扱われる.実行中に呼び出してはならない.
+func (i *Interpreter) Close() {
+	i.generators.killAll()
+}
+
+// reportRuntimeError はトップレベルまで抜けてきたエラーをDiagnosticsに報告して返す.
+func (i *Interpreter) reportRuntimeError(err error) error {
+	unwind(err, CallFrame{Function: "<script>", Script: i.ScriptPath}, 0)
+	i.Diagnostics.Report(newRuntimeDiagnostic(err))
+	return err
+}
+
+// Context は実行中のInterpretContextに渡されたcontext.
+// 時間のかかるネイティブ関数はこれを見て,contextが終了したら処理を打ち切る.
+func (i *Interpreter) Context() context.Context {
+	return i.executionBudget().ctx
+}
+
+// executionBudget は実行中のコードが使う資源.ジェネレータの本体では,本体を再開した呼び出し側のものになる.
+func (i *Interpreter) executionBudget() *executionBudget {
+	if i.generator != nil {
+		return i.generator.budget
 	}
-	right := i.evaluate(expr.Right)
-	if v, ok := right.(error); ok {
-		return v
+	return i.budget
+}
+
+func (i *Interpreter) VisitBinaryExpr(expr *Binary) (any, error) {
+	left, err := i.evaluate(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := i.evaluate(expr.Right)
+	if err != nil {
+		return nil, err
 	}
 
-	switch expr.Operator.Typ {
+	return i.binaryOperation(expr.Operator, left, right)
+}
+
+// binaryOperation はoperatorの種類に応じた二項演算を行う.複合代入からも使われる.
+func (i *Interpreter) binaryOperation(operator Token, left, right any) (any, error) {
+	switch operator.Typ {
 	case BANG_EQUAL:
-		return !i.isEqual(left, right)
+		return !i.isEqual(left, right), nil
 	case EQUAL_EQUAL:
-		return i.isEqual(left, right)
+		return i.isEqual(left, right), nil
 	case GREATER:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 > a2 })
-		return value
+		return i.checkComparisonOperands(operator, left, right, func(c int) bool { return c > 0 })
 	case GREATER_EQUAL:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 >= a2 })
-		return value
+		return i.checkComparisonOperands(operator, left, right, func(c int) bool { return c >= 0 })
 	case LESS:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 < a2 })
-		return value
+		return i.checkComparisonOperands(operator, left, right, func(c int) bool { return c < 0 })
 	case LESS_EQUAL:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 <= a2 })
-		return value
+		return i.checkComparisonOperands(operator, left, right, func(c int) bool { return c <= 0 })
 	case MINUS:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 - a2 })
-		return value
+		return i.checkNumberOperands(operator, left, right, subtraction)
 	case PLUS:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 + a2 })
-		if v, ok := value.(float64); ok {
-			return v
+		if isNumber(left) && isNumber(right) {
+			return addition.apply(left, right), nil
 		}
-		value = i.checkStringOperands(expr.Operator, left, right, func(a1, a2 string) any { return a1 + a2 })
-		if v, ok := value.(string); ok {
-			return v
+		vl, okl := left.(string)
+		vr, okr := right.(string)
+		if okl && okr {
+			return vl + vr, nil
 		}
 
-		return NewRuntimeError(expr.Operator, "Operands must be two numbers or two strings.")
+		return nil, NewRuntimeError(operator, "Operands must be two numbers or two strings.")
 	case SLASH:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 / a2 })
-		return value
+		return i.checkNumberOperands(operator, left, right, division)
 	case STAR:
-		value := i.checkNumberOperands(expr.Operator, left, right, func(a1, a2 float64) any { return a1 * a2 })
-		return value
+		if err := i.checkProductSize(operator, left, right); err != nil {
+			return nil, err
+		}
+		return i.checkNumberOperands(operator, left, right, multiplication)
+	case PERCENT:
+		// 剰余の符号は除数と同じになる(ex: -7 % 3 == 2).
+		if err := i.checkDivisor(operator, right); err != nil {
+			return nil, err
+		}
+		return i.checkNumberOperands(operator, left, right, numberOperation{
+			integer: func(a1, a2 int64) (any, bool) {
+				r := a1 % a2
+				if r != 0 && (r < 0) != (a2 < 0) {
+					r += a2
+				}
+				return r, true
+			},
+			big: func(a1, a2 *big.Int) any {
+				r := new(big.Int).Rem(a1, a2)
+				if r.Sign() != 0 && r.Sign() != a2.Sign() {
+					r.Add(r, a2)
+				}
+				return r
+			},
+			float: func(a1, a2 float64) any { return a1 - a2*math.Floor(a1/a2) },
+		})
+	case TILDE_SLASH:
+		// 整数除算は負の無限大の方向に丸める(ex: -7 ~/ 2 == -4).
+		if err := i.checkDivisor(operator, right); err != nil {
+			return nil, err
+		}
+		return i.checkNumberOperands(operator, left, right, numberOperation{
+			integer: func(a1, a2 int64) (any, bool) {
+				if a1 == math.MinInt64 && a2 == -1 {
+					return nil, false
+				}
+				q := a1 / a2
+				if a1%a2 != 0 && (a1 < 0) != (a2 < 0) {
+					q--
+				}
+				return q, true
+			},
+			big: func(a1, a2 *big.Int) any {
+				q, r := new(big.Int).QuoRem(a1, a2, new(big.Int))
+				if r.Sign() != 0 && r.Sign() != a2.Sign() {
+					q.Sub(q, big.NewInt(1))
+				}
+				return q
+			},
+			float: func(a1, a2 float64) any { return math.Floor(a1 / a2) },
+		})
+	case STAR_STAR:
+		// 整数の非負の整数乗は正確に計算し,それ以外は浮動小数点数で計算する.
+		if err := i.checkPowerSize(operator, left, right); err != nil {
+			return nil, err
+		}
+		return i.checkNumberOperands(operator, left, right, numberOperation{
+			integer: func(a1, a2 int64) (any, bool) {
+				return nil, false
+			},
+			big: func(a1, a2 *big.Int) any {
+				if a2.Sign() < 0 || !a2.IsInt64() {
+					return math.Pow(toFloat(a1), toFloat(a2))
+				}
+				return new(big.Int).Exp(a1, a2, nil)
+			},
+			float: func(a1, a2 float64) any { return math.Pow(a1, a2) },
+		})
+	case AMPERSAND:
+		return i.checkIntegerOperands(operator, left, right, func(a1, a2 *big.Int) (*big.Int, error) {
+			return new(big.Int).And(a1, a2), nil
+		})
+	case PIPE:
+		return i.checkIntegerOperands(operator, left, right, func(a1, a2 *big.Int) (*big.Int, error) {
+			return new(big.Int).Or(a1, a2), nil
+		})
+	case CARET:
+		return i.checkIntegerOperands(operator, left, right, func(a1, a2 *big.Int) (*big.Int, error) {
+			return new(big.Int).Xor(a1, a2), nil
+		})
+	case LESS_LESS:
+		return i.checkIntegerOperands(operator, left, right, func(a1, a2 *big.Int) (*big.Int, error) {
+			if a2.Sign() < 0 {
+				return nil, NewRuntimeError(operator, "Shift count must be non-negative.")
+			}
+			if !a2.IsUint64() || a2.Uint64() > maxShift {
+				return nil, NewRuntimeError(operator, "Shift count is too large.")
+			}
+			return new(big.Int).Lsh(a1, uint(a2.Uint64())), nil
+		})
+	case GREATER_GREATER:
+		return i.checkIntegerOperands(operator, left, right, func(a1, a2 *big.Int) (*big.Int, error) {
+			if a2.Sign() < 0 {
+				return nil, NewRuntimeError(operator, "Shift count must be non-negative.")
+			}
+			if !a2.IsUint64() || a2.Uint64() > maxShift {
+				if a1.Sign() < 0 {
+					return big.NewInt(-1), nil
+				}
+				return big.NewInt(0), nil
+			}
+			return new(big.Int).Rsh(a1, uint(a2.Uint64())), nil
+		})
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (i *Interpreter) VisitCallExpr(expr Call) any {
-	callee := i.evaluate(expr.Callee)
-	if v, ok := callee.(error); ok {
-		return v
+func (i *Interpreter) VisitCallExpr(expr *Call) (any, error) {
+	callee, err := i.evaluate(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := callee.(skippedChain); ok || (expr.Optional && callee == nil) {
+		return skippedChain{}, nil
 	}
 
-	arguments := make([]any, 0)
+	arguments := make([]any, 0, len(expr.Arguments))
 	for _, argument := range expr.Arguments {
-		arg := i.evaluate(argument)
-		if v, ok := arg.(error); ok {
-			return v
+		arg, err := i.evaluate(argument)
+		if err != nil {
+			return nil, err
 		}
 		arguments = append(arguments, arg)
 	}
 
-	if function, ok := callee.(LoxCallable); ok {
-		if len(arguments) != function.Arity() {
-			return NewRuntimeError(expr.Paren, "Expected "+fmt.Sprint(function.Arity())+" arguments but got "+fmt.Sprint(len(arguments))+".")
-		}
-		return function.Call(*i, arguments)
+	return i.callValue(expr.Paren, callee, arguments)
+}
+
+// callValue はloxの値calleeを引数argumentsで呼び出す.tokenはランタイムエラー時の場所報告用のトークン.
+func (i *Interpreter) callValue(token Token, callee any, arguments []any) (any, error) {
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		return nil, NewRuntimeError(token, "Can only call functions and classes.")
+	}
+	min, max := function.Arity()
+	if len(arguments) < min || (max >= 0 && len(arguments) > max) {
+		return nil, NewRuntimeError(token, "Expected "+arityString(min, max)+" arguments but got "+fmt.Sprint(len(arguments))+".")
 	}
-	return NewRuntimeError(expr.Paren, "Can only call functions and classes.")
+
+	if i.MaxCallDepth > 0 && len(i.frames) >= i.MaxCallDepth {
+		return nil, NewRuntimeError(token, "Stack overflow.")
+	}
+
+	if err := i.executionBudget().step(); err != nil {
+		return nil, err
+	}
+
+	i.pushFrame(function)
+	value, err := function.Call(i, arguments)
+	// ネイティブ関数はトークンを持たないので,呼び出し箇所の括弧をエラーの場所とする.
+	err = withLocation(token, err)
+	i.popFrame(token, err)
+	return value, err
 }
 
-func (i *Interpreter) VisitUnaryExpr(expr Unary) any {
-	right := i.evaluate(expr.Right)
-	if v, ok := right.(error); ok {
-		return v
+// arityString は引数の数の範囲をエラーメッセージ用の文字列にする.
+func arityString(min, max int) string {
+	switch {
+	case max < 0:
+		return "at least " + fmt.Sprint(min)
+	case min == max:
+		return fmt.Sprint(min)
+	}
+	return fmt.Sprint(min) + " to " + fmt.Sprint(max)
+}
+
+func (i *Interpreter) VisitConditionalExpr(expr *Conditional) (any, error) {
+	condition, err := i.evaluate(expr.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.isTruthy(condition) {
+		return i.evaluate(expr.ThenBranch)
+	}
+	return i.evaluate(expr.ElseBranch)
+}
+
+func (i *Interpreter) VisitGetExpr(expr *Get) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := object.(skippedChain); ok || (expr.Optional && object == nil) {
+		return skippedChain{}, nil
+	}
+	switch v := object.(type) {
+	case *LoxInstance:
+		return v.get(expr.Name)
+	case *LoxModule:
+		return v.get(expr.Name)
+	case *LoxGenerator:
+		return v.get(expr.Name)
+	}
+
+	return nil, NewRuntimeError(expr.Name, "Only instances, modules and generators have properties.")
+}
+
+// skippedChain は?.の左辺がnilだったために,残りの呼び出しやプロパティ参照を飛ばしていることを表す.
+// OptionalChainの外には出ずに,nilに置き換えられる.
+type skippedChain struct{}
+
+func (i *Interpreter) VisitOptionalChainExpr(expr *OptionalChain) (any, error) {
+	value, err := i.evaluate(expr.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(skippedChain); ok {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitSetExpr(expr *Set) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		return nil, NewRuntimeError(expr.Name, "Only instances have fields.")
+	}
+
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	instance.set(expr.Name, value)
+	return value, nil
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *Super) (any, error) {
+	distance := i.Locals[expr]
+	superclass := i.Environment.getAt(distance, "super").(*LoxClass)
+
+	// thisは常にsuperの環境のすぐ内側にある.
+	object := i.Environment.getAt(distance-1, "this").(*LoxInstance)
+
+	method := superclass.findMethod(expr.Method.Lexeme)
+	if method == nil {
+		return nil, NewRuntimeError(expr.Method, "Undefined property '"+expr.Method.Lexeme+"'.")
+	}
+
+	return method.bind(object), nil
+}
+
+func (i *Interpreter) VisitSetSubscriptExpr(expr *SetSubscript) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch object.(type) {
+	case *LoxList, *LoxMap:
+	default:
+		return nil, NewRuntimeError(expr.Bracket, "Only lists and maps can be indexed.")
+	}
+
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.setSubscript(expr.Bracket, object, index, value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitThisExpr(expr *This) (any, error) {
+	return i.lookUpVariable(expr.Keyword, expr)
+}
+
+func (i *Interpreter) VisitUnaryExpr(expr *Unary) (any, error) {
+	right, err := i.evaluate(expr.Right)
+	if err != nil {
+		return nil, err
 	}
 
 	switch expr.Operator.Typ {
 	case BANG:
-		return !i.isTruthy(right)
+		return !i.isTruthy(right), nil
 	case MINUS:
-		value := i.checkNumberOperand(expr.Operator, right, func(f float64) any { return -f })
-		return value
+		if isNumber(right) {
+			return negateNumber(right), nil
+		}
+		return nil, NewRuntimeError(expr.Operator, "Operand must be a number.")
+	case TILDE:
+		if v, ok := toBigInteger(right); ok {
+			return normalizeBig(new(big.Int).Not(v)), nil
+		}
+		return nil, NewRuntimeError(expr.Operator, "Operand must be an integer.")
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (i *Interpreter) VisitGroupingExpr(expr Grouping) any {
+func (i *Interpreter) VisitGroupingExpr(expr *Grouping) (any, error) {
 	return i.evaluate(expr.Expression)
 }
 
-func (i *Interpreter) VisitLiteralExpr(expr Literal) any {
-	return expr.Value
+func (i *Interpreter) VisitSubscriptExpr(expr *Subscript) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := object.(skippedChain); ok {
+		return skippedChain{}, nil
+	}
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.getSubscript(expr.Bracket, object, index)
+}
+
+func (i *Interpreter) getSubscript(bracket Token, object, index any) (any, error) {
+	switch v := object.(type) {
+	case *LoxList:
+		return v.get(bracket, index)
+	case *LoxMap:
+		return v.get(index), nil
+	}
+
+	return nil, NewRuntimeError(bracket, "Only lists and maps can be indexed.")
+}
+
+func (i *Interpreter) setSubscript(bracket Token, object, index, value any) error {
+	switch v := object.(type) {
+	case *LoxList:
+		return v.set(bracket, index, value)
+	case *LoxMap:
+		v.set(index, value)
+		return nil
+	}
+
+	return NewRuntimeError(bracket, "Only lists and maps can be indexed.")
+}
+
+func (i *Interpreter) VisitInterpolationExpr(expr *Interpolation) (any, error) {
+	var builder strings.Builder
+	for _, part := range expr.Parts {
+		value, err := i.evaluate(part)
+		if err != nil {
+			return nil, err
+		}
+		builder.WriteString(stringify(value))
+	}
+
+	return builder.String(), nil
+}
+
+func (i *Interpreter) VisitLambdaExpr(expr *Lambda) (any, error) {
+	return NewLoxFunction(expr.Declaration, i.Environment, i.Globals, i.ScriptPath, false), nil
+}
+
+func (i *Interpreter) VisitListExpr(expr *List) (any, error) {
+	elements := make([]any, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		value, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+
+	return NewLoxList(elements), nil
+}
+
+func (i *Interpreter) VisitMapExpr(expr *Map) (any, error) {
+	loxMap := NewLoxMap()
+	for n, key := range expr.Keys {
+		k, err := i.evaluate(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := i.evaluate(expr.Values[n])
+		if err != nil {
+			return nil, err
+		}
+		loxMap.set(k, value)
+	}
+
+	return loxMap, nil
+}
+
+func (i *Interpreter) VisitLiteralExpr(expr *Literal) (any, error) {
+	return expr.Value, nil
 }
 
-func (i *Interpreter) VisitLogicalExpr(expr Logical) any {
-	left := i.evaluate(expr.Left)
-	if err, ok := left.(error); ok {
-		return err
+func (i *Interpreter) VisitLogicalExpr(expr *Logical) (any, error) {
+	left, err := i.evaluate(expr.Left)
+	if err != nil {
+		return nil, err
 	}
 
 	if expr.Operator.Typ == OR {
 		if i.isTruthy(left) {
-			return left
+			return left, nil
+		}
+	} else if expr.Operator.Typ == QUESTION_QUESTION {
+		if left != nil {
+			return left, nil
 		}
 	} else {
 		if !i.isTruthy(left) {
-			return left
+			return left, nil
 		}
 	}
 	return i.evaluate(expr.Right)
 }
 
-func (i *Interpreter) VisitVariableExpr(expr Variable) any {
-	value, err := i.lookUpVariable(expr.Name, expr)
-	if err != nil {
-		return err
-	}
-	return value
+func (i *Interpreter) VisitVariableExpr(expr *Variable) (any, error) {
+	return i.lookUpVariable(expr.Name, expr)
 }
 
 func (i *Interpreter) lookUpVariable(name Token, expr Expr) (any, error) {
@@ -169,28 +595,123 @@ func (i *Interpreter) lookUpVariable(name Token, expr Expr) (any, error) {
 	return i.Globals.get(name)
 }
 
-func (i *Interpreter) VisitAssignExpr(expr Assign) any {
-	value := i.evaluate(expr.Value)
-	if err, ok := value.(error); ok {
-		return err
+func (i *Interpreter) VisitAssignExpr(expr *Assign) (any, error) {
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.assignVariable(expr.Name, expr, value)
+	if err != nil {
+		return nil, err
 	}
+	return value, nil
+}
 
+// assignVariable はexprに対して変数解決された深さの変数nameにvalueを代入する.
+func (i *Interpreter) assignVariable(name Token, expr Expr, value any) error {
 	if distance, ok := i.Locals[expr]; ok {
-		i.Environment.assignAt(distance, expr.Name, value)
-	} else {
-		err := i.Globals.assign(expr.Name, value)
+		i.Environment.assignAt(distance, name, value)
+		return nil
+	}
+	return i.Globals.assign(name, value)
+}
+
+// compoundOperators は複合代入とインクリメント,デクリメントの演算子を対応する二項演算子に変換する.
+var compoundOperators = map[TokenType]TokenType{
+	PLUS_EQUAL:  PLUS,
+	MINUS_EQUAL: MINUS,
+	STAR_EQUAL:  STAR,
+	SLASH_EQUAL: SLASH,
+	PLUS_PLUS:   PLUS,
+	MINUS_MINUS: MINUS,
+}
+
+// VisitCompoundAssignExpr は複合代入(+=など)とインクリメント,デクリメントを実行する.
+// 代入先のオブジェクトや添字は一度だけ評価される.後置の場合は更新前の値を返す.
+func (i *Interpreter) VisitCompoundAssignExpr(expr *CompoundAssign) (any, error) {
+	operator := *NewToken(compoundOperators[expr.Operator.Typ], expr.Operator.Lexeme, nil, expr.Operator.Line)
+	update := func(current any) (any, error) {
+		value, err := i.evaluate(expr.Value)
+		if err != nil {
+			return nil, err
+		}
+		return i.binaryOperation(operator, current, value)
+	}
+
+	var current, result any
+	switch target := expr.Target.(type) {
+	case *Variable:
+		value, err := i.lookUpVariable(target.Name, expr)
+		if err != nil {
+			return nil, err
+		}
+		current = value
+		result, err = update(current)
+		if err != nil {
+			return nil, err
+		}
+		err = i.assignVariable(target.Name, expr, result)
+		if err != nil {
+			return nil, err
+		}
+	case *Get:
+		object, err := i.evaluate(target.Object)
+		if err != nil {
+			return nil, err
+		}
+		instance, ok := object.(*LoxInstance)
+		if !ok {
+			return nil, NewRuntimeError(target.Name, "Only instances have fields.")
+		}
+		value, err := instance.get(target.Name)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		current = value
+		result, err = update(current)
+		if err != nil {
+			return nil, err
+		}
+		instance.set(target.Name, result)
+	case *Subscript:
+		object, err := i.evaluate(target.Object)
+		if err != nil {
+			return nil, err
+		}
+		index, err := i.evaluate(target.Index)
+		if err != nil {
+			return nil, err
+		}
+		value, err := i.getSubscript(target.Bracket, object, index)
+		if err != nil {
+			return nil, err
+		}
+		current = value
+		result, err = update(current)
+		if err != nil {
+			return nil, err
+		}
+		err = i.setSubscript(target.Bracket, object, index, result)
+		if err != nil {
+			return nil, err
 		}
 	}
-	return value
+
+	if expr.Postfix {
+		return current, nil
+	}
+	return result, nil
 }
 
-func (i *Interpreter) evaluate(expr Expr) any {
+func (i *Interpreter) evaluate(expr Expr) (any, error) {
 	return expr.Accept(i)
 }
 
-func (i *Interpreter) execute(stmt Stmt) any {
+func (i *Interpreter) execute(stmt Stmt) Completion {
+	if err := i.executionBudget().step(); err != nil {
+		return NewErrorCompletion(err)
+	}
 	return stmt.Accept(i)
 }
 
@@ -198,7 +719,8 @@ func (i *Interpreter) resolve(expr Expr, depth int) {
 	i.Locals[expr] = depth
 }
 
-func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment) any {
+// executeBlock はenvironmentの中でstatementsを順に実行し,正常に完了しなかった最初の文の結果を返す.
+func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment) Completion {
 	previous := i.Environment
 	defer func() {
 		i.Environment = previous
@@ -207,92 +729,368 @@ func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment)
 	i.Environment = environment
 
 	for _, statement := range statements {
-		err := i.execute(statement)
-		if err != nil {
-			return err
+		completion := i.execute(statement)
+		if !completion.isNormal() {
+			return completion
 		}
 	}
 
-	return nil
+	return Completion{}
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt *Block) Completion {
+	return i.executeBlock(stmt.Statements, NewEnvironment().ChangeEnclosing(i.Environment))
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *Break) Completion {
+	return Completion{Kind: BREAK_COMPLETION}
 }
 
-func (i *Interpreter) VisitBlockStmt(stmt Block) any {
-	err := i.executeBlock(stmt.Statements, NewEnvironment().ChangeEnclosing(i.Environment))
+func (i *Interpreter) VisitClassStmt(stmt *Class) Completion {
+	var superclass *LoxClass
+	if stmt.Superclass != nil {
+		value, err := i.evaluate(stmt.Superclass)
+		if err != nil {
+			return NewErrorCompletion(err)
+		}
+		klass, ok := value.(*LoxClass)
+		if !ok {
+			return NewErrorCompletion(NewRuntimeError(stmt.Superclass.Name, "Superclass must be a class."))
+		}
+		superclass = klass
+	}
+
+	if err := i.Environment.declare(stmt.Name, nil); err != nil {
+		return NewErrorCompletion(err)
+	}
+
+	// superはメソッドのクロージャとクラス宣言の環境の間に挟んだ環境に束縛する.
+	if superclass != nil {
+		i.Environment = NewEnvironment().ChangeEnclosing(i.Environment)
+		i.Environment.define("super", superclass)
+	}
+
+	methods := map[string]*LoxFunction{}
+	for _, method := range stmt.Methods {
+		function := NewLoxFunction(method, i.Environment, i.Globals, i.ScriptPath, method.Name.Lexeme == "init")
+		methods[method.Name.Lexeme] = function
+	}
+
+	klass := NewLoxClass(stmt.Name.Lexeme, superclass, methods)
+
+	if superclass != nil {
+		i.Environment = i.Environment.Enclosing
+	}
+
+	err := i.Environment.assign(stmt.Name, klass)
 	if err != nil {
-		return err
+		return NewErrorCompletion(err)
 	}
-	return nil
+	return Completion{}
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *Continue) Completion {
+	return Completion{Kind: CONTINUE_COMPLETION}
 }
 
-func (i *Interpreter) VisitExpressStmt(stmt Express) any {
-	value := i.evaluate(stmt.Expression)
-	if err, ok := value.(error); ok {
-		return err
+func (i *Interpreter) VisitExpressStmt(stmt *Express) Completion {
+	_, err := i.evaluate(stmt.Expression)
+	if err != nil {
+		return NewErrorCompletion(err)
 	}
-	return nil
+	return Completion{}
 }
 
-func (i *Interpreter) VisitFunctionStmt(stmt Function) any {
-	function := NewLoxFunction(&stmt, i.Environment)
-	i.Environment.define(stmt.Name.Lexeme, function)
-	return nil
+func (i *Interpreter) VisitForInStmt(stmt *ForIn) Completion {
+	iterable, err := i.evaluate(stmt.Iterable)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+	iterator, err := i.iterator(stmt.In, iterable)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+
+	if generator, ok := iterator.(*LoxGenerator); ok {
+		generator.resumeWith(i)
+	}
+	completion := i.forInLoop(stmt, iterator)
+	// break,return,エラーでループを抜けた場合も,ジェネレータは終了させる.
+	if generator, ok := iterator.(*LoxGenerator); ok {
+		if exit, ok := completion.Err.(*GeneratorExit); ok && exit.killed {
+			return completion
+		}
+		if err := generator.close(); err != nil && completion.isNormal() {
+			return NewErrorCompletion(resumedAt(err, stmt.In))
+		}
+	}
+	return completion
 }
 
-func (i *Interpreter) VisitIfStmt(stmt If) any {
-	if i.isTruthy(i.evaluate(stmt.Condition)) {
-		err := i.execute(stmt.ThenBranch)
+func (i *Interpreter) forInLoop(stmt *ForIn, iterator loxIterator) Completion {
+	body := []Stmt{stmt.Body}
+	for {
+		value, ok, err := iterator.next()
 		if err != nil {
-			return err
+			return NewErrorCompletion(resumedAt(withLocation(stmt.In, err), stmt.In))
 		}
+		if !ok {
+			break
+		}
+
+		// クロージャがその回の値を捕捉できるように,反復ごとに新しい環境を作る.
+		environment := NewEnvironment().ChangeEnclosing(i.Environment)
+		environment.define(stmt.Name.Lexeme, value)
+		completion := i.executeBlock(body, environment)
+		switch completion.Kind {
+		case BREAK_COMPLETION:
+			return Completion{}
+		case RETURN_COMPLETION, ERROR_COMPLETION:
+			return completion
+		}
+	}
+
+	return Completion{}
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt *Function) Completion {
+	function := NewLoxFunction(stmt, i.Environment, i.Globals, i.ScriptPath, false)
+	if err := i.Environment.declare(stmt.Name, function); err != nil {
+		return NewErrorCompletion(err)
+	}
+	return Completion{}
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *If) Completion {
+	condition, err := i.evaluate(stmt.Condition)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+
+	if i.isTruthy(condition) {
+		return i.execute(stmt.ThenBranch)
 	} else if stmt.ElseBranch != nil {
-		err := i.execute(stmt.ElseBranch)
+		return i.execute(stmt.ElseBranch)
+	}
+	return Completion{}
+}
+
+func (i *Interpreter) VisitImportStmt(stmt *Import) Completion {
+	module, err := i.loadModule(stmt.Path)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+
+	if stmt.Alias != nil {
+		if err := i.Environment.declare(*stmt.Alias, module); err != nil {
+			return NewErrorCompletion(err)
+		}
+	}
+	for _, name := range stmt.Names {
+		value, err := module.get(name)
 		if err != nil {
-			return err
+			return NewErrorCompletion(err)
+		}
+		if err := i.Environment.declare(name, value); err != nil {
+			return NewErrorCompletion(err)
 		}
 	}
-	return nil
+	return Completion{}
 }
 
-func (i *Interpreter) VisitPrintStmt(stmt Print) any {
-	value := i.evaluate(stmt.Expression)
-	if err, ok := value.(error); ok {
-		return err
+func (i *Interpreter) VisitMatchStmt(stmt *Match) Completion {
+	subject, err := i.evaluate(stmt.Subject)
+	if err != nil {
+		return NewErrorCompletion(err)
 	}
-	fmt.Println(i.stringify(value))
-	return nil
+
+	for _, arm := range stmt.Arms {
+		if !i.matchPatterns(arm.Patterns, subject) {
+			continue
+		}
+
+		environment := NewEnvironment().ChangeEnclosing(i.Environment)
+		for _, name := range arm.bindings() {
+			environment.define(name.Lexeme, subject)
+		}
+
+		if arm.Guard != nil {
+			previous := i.Environment
+			i.Environment = environment
+			guard, err := i.evaluate(arm.Guard)
+			i.Environment = previous
+			if err != nil {
+				return NewErrorCompletion(err)
+			}
+			if !i.isTruthy(guard) {
+				continue
+			}
+		}
+
+		return i.executeBlock([]Stmt{arm.Body}, environment)
+	}
+
+	return NewErrorCompletion(NewRuntimeError(stmt.Keyword, "No match arm for value '"+stringify(subject)+"'."))
+}
+
+// matchPatterns はvalueがpatternsのいずれかにマッチするかを返す.patternsがnilのelse節は常にマッチする.
+func (i *Interpreter) matchPatterns(patterns []Pattern, value any) bool {
+	if patterns == nil {
+		return true
+	}
+	for _, pattern := range patterns {
+		switch pattern.Kind {
+		case LITERAL_PATTERN:
+			if i.isEqual(value, pattern.Value) {
+				return true
+			}
+		case RANGE_PATTERN:
+			if !isNumber(value) {
+				continue
+			}
+			low, okLow := compareNumbers(pattern.Low, value)
+			high, okHigh := compareNumbers(value, pattern.High)
+			if okLow && okHigh && low <= 0 && high <= 0 {
+				return true
+			}
+		case BINDING_PATTERN:
+			return true
+		}
+	}
+	return false
 }
 
-func (i *Interpreter) VisitReturnStmt(stmt Return) any {
+func (i *Interpreter) VisitPrintStmt(stmt *Print) Completion {
+	value, err := i.evaluate(stmt.Expression)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+	fmt.Println(stringify(value))
+	return Completion{}
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt *Return) Completion {
 	var value any = nil
 	if stmt.Value != nil {
-		value = i.evaluate(stmt.Value)
-		if err, ok := value.(error); ok {
-			return err
+		var err error
+		value, err = i.evaluate(stmt.Value)
+		if err != nil {
+			return NewErrorCompletion(err)
 		}
 	}
 
-	return NewReturnValue(value)
+	return NewReturnCompletion(value)
 }
 
-func (i *Interpreter) VisitVarStmt(stmt Var) any {
+func (i *Interpreter) VisitThrowStmt(stmt *Throw) Completion {
+	value, err := i.evaluate(stmt.Value)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+
+	return NewErrorCompletion(NewThrowError(stmt.Keyword, value))
+}
+
+func (i *Interpreter) VisitTryStmt(stmt *Try) Completion {
+	completion := i.executeBlock(stmt.Body, NewEnvironment().ChangeEnclosing(i.Environment))
+	// 参照されなくなったジェネレータの後始末では,loxのコードを実行せずに巻き戻す.
+	if exit, ok := completion.Err.(*GeneratorExit); ok && exit.killed {
+		return completion
+	}
+
+	if stmt.CatchParam != nil && completion.Kind == ERROR_COMPLETION {
+		// catchできるのはthrowされた値とランタイムエラーだけで,return,break,continueは素通りさせる.
+		var caught any
+		isCaught := true
+		switch v := completion.Err.(type) {
+		case *ThrowError:
+			caught = v.Value
+		case *RuntimeError:
+			caught = newErrorValue(v)
+		default:
+			isCaught = false
+		}
+
+		if isCaught {
+			environment := NewEnvironment().ChangeEnclosing(i.Environment)
+			environment.define(stmt.CatchParam.Lexeme, caught)
+			completion = i.executeBlock(stmt.CatchBody, environment)
+		}
+	}
+
+	if stmt.FinallyBody != nil {
+		// finallyの中でのreturnやエラーは,tryやcatchの結果よりも優先される.
+		finally := i.executeBlock(stmt.FinallyBody, NewEnvironment().ChangeEnclosing(i.Environment))
+		if !finally.isNormal() {
+			return finally
+		}
+	}
+
+	return completion
+}
+
+func (i *Interpreter) VisitVarStmt(stmt *Var) Completion {
 	var value any
 	if stmt.Initializer != nil {
-		value = i.evaluate(stmt.Initializer)
-		if err, ok := value.(error); ok {
-			return err
+		var err error
+		value, err = i.evaluate(stmt.Initializer)
+		if err != nil {
+			return NewErrorCompletion(err)
 		}
 	}
 
-	i.Environment.define(stmt.Name.Lexeme, value)
-	return nil
+	declare := i.Environment.declare
+	if stmt.Constant {
+		declare = i.Environment.declareConstant
+	}
+	if err := declare(stmt.Name, value); err != nil {
+		return NewErrorCompletion(err)
+	}
+	return Completion{}
 }
 
-func (i *Interpreter) VisitWhileStmt(stmt While) any {
-	for i.isTruthy(i.evaluate(stmt.condition)) {
-		i.execute(stmt.body)
+func (i *Interpreter) VisitWhileStmt(stmt *While) Completion {
+	for {
+		condition, err := i.evaluate(stmt.condition)
+		if err != nil {
+			return NewErrorCompletion(err)
+		}
+		if !i.isTruthy(condition) {
+			break
+		}
+
+		// continueの場合もforのインクリメントは実行する.
+		completion := i.execute(stmt.body)
+		switch completion.Kind {
+		case BREAK_COMPLETION:
+			return Completion{}
+		case RETURN_COMPLETION, ERROR_COMPLETION:
+			return completion
+		}
+
+		if stmt.increment != nil {
+			_, err := i.evaluate(stmt.increment)
+			if err != nil {
+				return NewErrorCompletion(err)
+			}
+		}
 	}
 
-	return nil
+	return Completion{}
+}
+
+func (i *Interpreter) VisitYieldStmt(stmt *Yield) Completion {
+	value, err := i.evaluate(stmt.Value)
+	if err != nil {
+		return NewErrorCompletion(err)
+	}
+	if i.generator == nil {
+		return NewErrorCompletion(NewRuntimeError(stmt.Keyword, "Can't yield outside of a generator."))
+	}
+	if err := i.generator.yield(stmt.Keyword, value); err != nil {
+		return NewErrorCompletion(err)
+	}
+	return Completion{}
 }
 
 func (i *Interpreter) isTruthy(object any) bool {
@@ -311,40 +1109,108 @@ func (i *Interpreter) isEqual(a, b any) bool {
 	if a == nil {
 		return false
 	}
+	// 数値は種類が異なっても値が等しければ等しい(ex: 1 == 1.0).
+	if isNumber(a) && isNumber(b) {
+		c, ok := compareNumbers(a, b)
+		return ok && c == 0
+	}
 	return a == b
 }
 
-func (i *Interpreter) stringify(object any) any {
+// stringify はloxの値をprintで表示する文字列に変換する.
+func stringify(object any) string {
 	if object == nil {
 		return "nil"
 	}
-	return object
+	if isNumber(object) {
+		return formatNumber(object)
+	}
+	return fmt.Sprint(object)
+}
+
+// maxShift はシフト演算で許すシフト量の上限.巨大な整数を作ってメモリを使い果たさないようにする.
+const maxShift = 1 << 20
+
+// checkPowerSize は整数の累乗の結果がmaxShiftビットより大きくなる場合にエラーを返す.
+// 結果のビット数は底のビット数と指数の積で見積もる.
+func (i *Interpreter) checkPowerSize(operator Token, base, exponent any) error {
+	b, e := toBig(base), toBig(exponent)
+	if b == nil || e == nil || e.Sign() <= 0 || b.CmpAbs(big.NewInt(1)) <= 0 {
+		return nil
+	}
+	if !e.IsInt64() || e.Int64() > maxShift/int64(b.BitLen()) {
+		return NewRuntimeError(operator, "Result of '**' is too large.")
+	}
+	return nil
+}
+
+// checkProductSize は整数の積の結果がmaxShiftビットより大きくなる場合にエラーを返す.
+// 結果のビット数は2つの整数のビット数の和で見積もる.
+func (i *Interpreter) checkProductSize(operator Token, left, right any) error {
+	a, b := toBig(left), toBig(right)
+	if a == nil || b == nil || a.CmpAbs(big.NewInt(1)) <= 0 || b.CmpAbs(big.NewInt(1)) <= 0 {
+		return nil
+	}
+	if a.BitLen()+b.BitLen() > maxShift {
+		return NewRuntimeError(operator, "Result of '*' is too large.")
+	}
+	return nil
 }
 
-func (i *Interpreter) checkNumberOperand(operator Token, operand any, calc func(float64) any) any {
-	if v, ok := operand.(float64); ok {
-		return calc(v)
+func (i *Interpreter) checkNumberOperands(operator Token, left, right any, operation numberOperation) (any, error) {
+	if isNumber(left) && isNumber(right) {
+		return operation.apply(left, right), nil
 	}
 
-	return NewRuntimeError(operator, "Operand must be a number.")
+	return nil, NewRuntimeError(operator, "Operand must be a numbers.")
 }
 
-func (i *Interpreter) checkNumberOperands(operator Token, left, right any, calc func(float64, float64) any) any {
-	vl, okl := left.(float64)
-	vr, okr := right.(float64)
-	if okl && okr {
-		return calc(vl, vr)
+func (i *Interpreter) checkComparisonOperands(operator Token, left, right any, compare func(int) bool) (any, error) {
+	if isNumber(left) && isNumber(right) {
+		c, ok := compareNumbers(left, right)
+		// NaNとの比較は常に偽になる.
+		return ok && compare(c), nil
 	}
 
-	return NewRuntimeError(operator, "Operand must be a numbers.")
+	return nil, NewRuntimeError(operator, "Operand must be a numbers.")
+}
+
+// checkDivisor は剰余と整数除算の除数が0でないことを確かめる.
+func (i *Interpreter) checkDivisor(operator Token, divisor any) error {
+	if !isNumber(divisor) {
+		return nil
+	}
+	if c, ok := compareNumbers(divisor, int64(0)); ok && c == 0 {
+		return NewRuntimeError(operator, "Division by zero.")
+	}
+	return nil
 }
 
-func (i *Interpreter) checkStringOperands(operator Token, left, right any, calc func(string, string) any) any {
-	vl, okl := left.(string)
-	vr, okr := right.(string)
+// checkIntegerOperands は整数(または整数値を持つ浮動小数点数)どうしのビット演算を行う.結果は整数になる.
+func (i *Interpreter) checkIntegerOperands(operator Token, left, right any, calc func(*big.Int, *big.Int) (*big.Int, error)) (any, error) {
+	vl, okl := toBigInteger(left)
+	vr, okr := toBigInteger(right)
 	if okl && okr {
-		return calc(vl, vr)
+		value, err := calc(vl, vr)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeBig(value), nil
 	}
 
-	return NewRuntimeError(operator, "Operand must be a string.")
+	return nil, NewRuntimeError(operator, "Operands must be integers.")
+}
+
+// toInteger は整数,または整数値を持つ浮動小数点数をint64に変換する.int64に収まらない場合は変換できない.
+func toInteger(object any) (int64, bool) {
+	switch v := object.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
 }
@@ -1,6 +1,7 @@
 package mygolox
 
 type LoxCallable interface {
-	Arity() int
-	Call(interpreter Interpreter, arguments []any) any
+	// Arity は受け取れる引数の数の最小値と最大値を返す.最大値が-1の場合は上限が無い.
+	Arity() (int, int)
+	Call(interpreter *Interpreter, arguments []any) (any, error)
 }
@@ -0,0 +1,55 @@
+package mygolox
+
+// LoxClass はloxのクラスを表す構造体.java実装のloxにおけるLoxClassクラス.
+type LoxClass struct {
+	Name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+}
+
+// NewLoxClass はLoxClassのコンストラクタ.スーパークラスが無い場合はsuperclassにnilを渡す.
+func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		Name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+// findMethod はメソッドを探す.見つからなければスーパークラスを順にたどる.
+func (l *LoxClass) findMethod(name string) *LoxFunction {
+	if method, ok := l.methods[name]; ok {
+		return method
+	}
+
+	if l.superclass != nil {
+		return l.superclass.findMethod(name)
+	}
+
+	return nil
+}
+
+func (l *LoxClass) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	instance := NewLoxInstance(l)
+	initializer := l.findMethod("init")
+	if initializer != nil {
+		_, err := initializer.bind(instance).Call(interpreter, arguments)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+func (l *LoxClass) Arity() (int, int) {
+	initializer := l.findMethod("init")
+	if initializer == nil {
+		return 0, 0
+	}
+	return initializer.Arity()
+}
+
+func (l *LoxClass) String() string {
+	return l.Name
+}
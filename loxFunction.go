@@ -3,35 +3,103 @@ package mygolox
 type LoxFunction struct {
 	declaration *Function
 	closure     *Environment
+	// globals は関数が定義されたモジュールのトップレベルの環境.
+	globals *Environment
+	// script は関数が定義されたスクリプトのパス.トレースバックの表示に使う.
+	script        string
+	isInitializer bool
 }
 
-func NewLoxFunction(declaration *Function, closure *Environment) *LoxFunction {
+func NewLoxFunction(declaration *Function, closure *Environment, globals *Environment, script string, isInitializer bool) *LoxFunction {
 	return &LoxFunction{
-		declaration: declaration,
-		closure:     closure,
+		declaration:   declaration,
+		closure:       closure,
+		globals:       globals,
+		script:        script,
+		isInitializer: isInitializer,
 	}
 }
 
-func (l *LoxFunction) Call(interpreter Interpreter, arguments []any) any {
+// bind はthisにinstanceを束縛した環境をクロージャとする新しいLoxFunctionを返す.
+func (l *LoxFunction) bind(instance *LoxInstance) *LoxFunction {
 	environment := NewEnvironment().ChangeEnclosing(l.closure)
+	environment.define("this", instance)
+	return NewLoxFunction(l.declaration, environment, l.globals, l.script, l.isInitializer)
+}
+
+func (l *LoxFunction) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	// 解決されなかった変数は,呼び出し元ではなく関数が定義されたモジュールから探す.
+	// 本体の中で作る関数やクラスも,関数が定義されたスクリプトのものとして扱う.
+	previousGlobals, previousEnvironment, previousPath := interpreter.Globals, interpreter.Environment, interpreter.ScriptPath
+	defer func() {
+		interpreter.Globals, interpreter.Environment, interpreter.ScriptPath = previousGlobals, previousEnvironment, previousPath
+	}()
+	interpreter.Globals = l.globals
+	interpreter.ScriptPath = l.script
+
+	environment := NewEnvironment().ChangeEnclosing(l.closure)
+	// デフォルト値は呼び出しのたびに,それより前の仮引数が定義された環境で評価する.
+	interpreter.Environment = environment
 	for i, param := range l.declaration.Params {
-		environment.define(param.Lexeme, arguments[i])
+		if i < len(arguments) {
+			environment.define(param.Lexeme, arguments[i])
+			continue
+		}
+		value, err := interpreter.evaluate(l.declaration.Defaults[i])
+		if err != nil {
+			return nil, err
+		}
+		environment.define(param.Lexeme, value)
+	}
+	if l.declaration.Rest != nil {
+		rest := make([]any, 0)
+		if len(arguments) > len(l.declaration.Params) {
+			rest = append(rest, arguments[len(l.declaration.Params):]...)
+		}
+		environment.define(l.declaration.Rest.Lexeme, NewLoxList(rest))
+	}
+
+	// ジェネレータ関数は本体を実行せずに,引数を束縛した環境を持つジェネレータを返す.
+	// 本体は呼び出し元とは別のgoroutineで実行するので,Interpreterの複製を渡す.
+	if l.declaration.Generator {
+		return NewLoxGenerator(CallFrame{Function: l.name(), Script: l.script}, *interpreter, l.declaration.Body, environment), nil
 	}
 
-	ret := interpreter.executeBlock(l.declaration.Body, environment)
-	if err, ok := ret.(error); ok {
-		return err
+	completion := interpreter.executeBlock(l.declaration.Body, environment)
+	if completion.Kind == ERROR_COMPLETION {
+		return nil, completion.Err
 	}
-	if r, ok := ret.(*ReturnValue); ok {
-		return r.value
+	// 初期化子は常にthisを返す.
+	if l.isInitializer {
+		return l.closure.getAt(0, "this"), nil
 	}
-	return nil
+	if completion.Kind == RETURN_COMPLETION {
+		return completion.Value, nil
+	}
+	return nil, nil
 }
 
-func (l *LoxFunction) Arity() int {
-	return len(l.declaration.Params)
+func (l *LoxFunction) Arity() (int, int) {
+	required := 0
+	for _, defaultValue := range l.declaration.Defaults {
+		if defaultValue == nil {
+			required++
+		}
+	}
+	if l.declaration.Rest != nil {
+		return required, -1
+	}
+	return required, len(l.declaration.Params)
 }
 
 func (l *LoxFunction) String() string {
-	return "<fn " + l.declaration.Name.Lexeme + ">"
+	return "<fn " + l.name() + ">"
+}
+
+func (l *LoxFunction) name() string {
+	// 無名関数のNameには名前の代わりにfunキーワードのトークンが入っている.
+	if l.declaration.Name.Typ == FUN {
+		return "anonymous"
+	}
+	return l.declaration.Name.Lexeme
 }
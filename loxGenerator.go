@@ -0,0 +1,305 @@
+package mygolox
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// GeneratorExit はclose()されたジェネレータの本体を,中断したyield文から巻き戻すための構造体.
+// catchでは捕まえられず,finallyは実行される.
+// killedがtrueの場合は参照されなくなったジェネレータの後始末で,finallyも実行せずに終了する.
+type GeneratorExit struct {
+	killed bool
+}
+
+func (g *GeneratorExit) Error() string {
+	return "generator closed"
+}
+
+// generatorResult はジェネレータの本体を実行するgoroutineから呼び出し側に渡す結果.
+type generatorResult struct {
+	value any
+	done  bool
+	err   error
+}
+
+// generatorState はジェネレータの本体を実行するgoroutineと呼び出し側が共有する状態.
+// 本体と呼び出し側はチャネルで交互に実行され,同時に動くことはない.
+// goroutineがLoxGeneratorを参照しないようにして,LoxGeneratorをファイナライザで回収できるようにしている.
+type generatorState struct {
+	// resume は中断している本体を再開させる.closeすると本体はGeneratorExitで巻き戻される.
+	resume chan struct{}
+	// results は本体がyieldした値か,本体の終了を伝える.巻き戻した本体が終了を送るときにブロックしないようにバッファを持つ.
+	results chan generatorResult
+	closing bool
+	// killed は本体をfinallyも実行せずに終了させたかどうか.ファイナライザやInterpreter.Closeのgoroutineからも書き込む.
+	killed atomic.Bool
+	// budget は本体を再開した呼び出し側のInterpretContextの資源.本体の文と呼び出しはこれで数える.
+	budget *executionBudget
+}
+
+// yield は値を呼び出し側に渡して,次に再開されるまで本体を中断する.
+func (g *generatorState) yield(keyword Token, value any) error {
+	if g.closing {
+		return NewRuntimeError(keyword, "Can't yield from a closed generator.")
+	}
+	g.results <- generatorResult{value: value}
+	if _, ok := <-g.resume; !ok {
+		return &GeneratorExit{killed: g.killed.Load()}
+	}
+	return nil
+}
+
+// run は本体を最後まで実行して,終了を呼び出し側に伝える.
+// 本体から抜けたエラーのトレースバックにはジェネレータのフレームを加える.
+// 再開した箇所の行は呼び出し側でresumedAtを使って記録する.
+func (g *generatorState) run(frame CallFrame, interpreter Interpreter, body []Stmt, environment *Environment) {
+	interpreter.generator = g
+	completion := interpreter.executeBlock(body, environment)
+	if _, ok := completion.Err.(*GeneratorExit); ok {
+		g.results <- generatorResult{done: true}
+		return
+	}
+	unwind(completion.Err, frame, 0)
+	g.results <- generatorResult{done: true, err: completion.Err}
+}
+
+// suspendedGenerators は実行が始まって,まだ終了していないジェネレータの本体の集まり.
+// Interpreterの複製の間で共有し,Interpreter.Closeで残っている本体をまとめて終了させる.
+// ファイナライザは別のgoroutineで動くので,mutexで守る.
+type suspendedGenerators struct {
+	mu     sync.Mutex
+	states map[*generatorState]bool
+}
+
+func newSuspendedGenerators() *suspendedGenerators {
+	return &suspendedGenerators{
+		states: map[*generatorState]bool{},
+	}
+}
+
+func (s *suspendedGenerators) add(state *generatorState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state] = true
+}
+
+// remove はstateを取り除く.stateが残っていなかった場合はfalseを返す.
+func (s *suspendedGenerators) remove(state *generatorState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.states[state] {
+		return false
+	}
+	delete(s.states, state)
+	return true
+}
+
+// kill は中断しているstateの本体をfinallyも実行せずに終了させる.
+// waitがtrueの場合は本体のgoroutineが終了を伝えるまで待つ.
+func (s *suspendedGenerators) kill(state *generatorState, wait bool) {
+	if !s.remove(state) {
+		return
+	}
+	state.killed.Store(true)
+	close(state.resume)
+	if wait {
+		<-state.results
+	}
+}
+
+// killAll は中断しているすべての本体を終了させて,goroutineが終了するまで待つ.
+func (s *suspendedGenerators) killAll() {
+	s.mu.Lock()
+	states := make([]*generatorState, 0, len(s.states))
+	for state := range s.states {
+		states = append(states, state)
+	}
+	s.mu.Unlock()
+	for _, state := range states {
+		s.kill(state, true)
+	}
+}
+
+// resumedAt はジェネレータの本体から抜けたエラーに,ジェネレータを再開した箇所の行を記録する.
+func resumedAt(err error, token Token) error {
+	if traced, ok := err.(tracedError); ok {
+		traced.resumeAt(token.Line)
+	}
+	return err
+}
+
+// errGeneratorRunning はジェネレータの本体の中から,そのジェネレータ自身を再開または終了しようとしたエラー.
+var errGeneratorRunning = errors.New("Generator is already running.")
+
+// LoxGenerator はジェネレータ関数の呼び出しが返すジェネレータ.
+// 本体は最初のnextかhasNextで実行が始まり,yield文ごとに中断する.
+type LoxGenerator struct {
+	// frame はトレースバックに表示するジェネレータ関数のフレーム.
+	frame       CallFrame
+	interpreter Interpreter
+	body        []Stmt
+	environment *Environment
+	state       *generatorState
+	started     bool
+	finished    bool
+	// running は本体を実行中かどうか.本体の中から自分自身を再開しようとするとtrueになっている.
+	running bool
+	// peeked はhasNextが先に取り出しておいた値があるかどうか.
+	peeked bool
+	value  any
+}
+
+// NewLoxGenerator はLoxGeneratorのコンストラクタ.
+// 最後まで実行されずに参照されなくなったジェネレータは,ファイナライザが本体のgoroutineを終了させる.
+// ただしジェネレータが自分の本体の環境から参照できる場合は,中断しているgoroutineが参照を持ち続けるので回収されない.
+// そのような本体はInterpreter.Closeで終了させる.
+func NewLoxGenerator(frame CallFrame, interpreter Interpreter, body []Stmt, environment *Environment) *LoxGenerator {
+	// 本体の呼び出しの深さは生成した箇所から数える.呼び出し元とスタックを共有しないように複製する.
+	interpreter.frames = slices.Clone(interpreter.frames)
+	generator := &LoxGenerator{
+		frame:       frame,
+		interpreter: interpreter,
+		body:        body,
+		environment: environment,
+		state: &generatorState{
+			resume:  make(chan struct{}),
+			results: make(chan generatorResult, 1),
+			budget:  interpreter.executionBudget(),
+		},
+	}
+	runtime.SetFinalizer(generator, func(g *LoxGenerator) {
+		g.interpreter.generators.kill(g.state, false)
+	})
+	return generator
+}
+
+// resumeWith は以降の本体の実行を,呼び出し側のinterpreterの資源で数えるようにする.
+func (l *LoxGenerator) resumeWith(interpreter *Interpreter) {
+	l.state.budget = interpreter.executionBudget()
+}
+
+// advance は本体を次のyield文か終わりまで実行する.終わりまで実行した場合はokがfalseになる.
+func (l *LoxGenerator) advance() (any, bool, error) {
+	if l.finished || l.state.killed.Load() {
+		l.finished = true
+		return nil, false, nil
+	}
+	if l.running {
+		return nil, false, errGeneratorRunning
+	}
+	l.running = true
+	defer func() {
+		l.running = false
+	}()
+	if !l.started {
+		l.started = true
+		l.interpreter.generators.add(l.state)
+		go l.state.run(l.frame, l.interpreter, l.body, l.environment)
+	} else {
+		l.state.resume <- struct{}{}
+	}
+
+	result := <-l.state.results
+	if result.done {
+		l.finished = true
+		l.interpreter.generators.remove(l.state)
+		return nil, false, result.err
+	}
+	return result.value, true, nil
+}
+
+// next はloxIteratorとしてyieldされた値を順に返す.
+func (l *LoxGenerator) next() (any, bool, error) {
+	if l.peeked {
+		l.peeked = false
+		value := l.value
+		l.value = nil
+		return value, true, nil
+	}
+	return l.advance()
+}
+
+func (l *LoxGenerator) hasNext() (bool, error) {
+	if l.peeked {
+		return true, nil
+	}
+	value, ok, err := l.advance()
+	if err != nil || !ok {
+		return false, err
+	}
+	l.peeked = true
+	l.value = value
+	return true, nil
+}
+
+// close は中断している本体をfinallyを実行しながら巻き戻して終了させる.
+// 実行が始まっていない,または終了しているジェネレータでは何もしない.
+func (l *LoxGenerator) close() error {
+	l.peeked = false
+	l.value = nil
+	if !l.started || l.finished || l.state.killed.Load() {
+		l.finished = true
+		return nil
+	}
+	if l.running {
+		return errGeneratorRunning
+	}
+	if !l.interpreter.generators.remove(l.state) {
+		l.finished = true
+		return nil
+	}
+	l.state.closing = true
+	close(l.state.resume)
+	result := <-l.state.results
+	l.finished = true
+	return result.err
+}
+
+func (l *LoxGenerator) get(name Token) (any, error) {
+	switch name.Lexeme {
+	case "next", "hasNext", "close":
+		return &generatorMethod{generator: l, method: name.Lexeme}, nil
+	}
+	return nil, NewRuntimeError(name, "Undefined property '"+name.Lexeme+"'.")
+}
+
+func (l *LoxGenerator) String() string {
+	return "<generator " + l.frame.Function + ">"
+}
+
+// generatorMethod はジェネレータのnext,hasNext,closeメソッド.
+// nextは次にyieldされた値を返し,ジェネレータが終了している場合はnilを返す.
+type generatorMethod struct {
+	generator *LoxGenerator
+	method    string
+}
+
+func (g *generatorMethod) Arity() (int, int) {
+	return 0, 0
+}
+
+func (g *generatorMethod) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	g.generator.resumeWith(interpreter)
+	switch g.method {
+	case "next":
+		value, _, err := g.generator.next()
+		return value, err
+	case "hasNext":
+		return g.generator.hasNext()
+	case "close":
+		return nil, g.generator.close()
+	}
+	return nil, errors.New("Undefined generator method '" + g.method + "'.")
+}
+
+func (g *generatorMethod) name() string {
+	return g.method
+}
+
+func (g *generatorMethod) String() string {
+	return "<native fn>"
+}
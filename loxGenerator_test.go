@@ -0,0 +1,85 @@
+package mygolox
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitGoroutines はgoroutineの数がwant以下になるまで少し待って,最後に数えた数を返す.
+func waitGoroutines(want int) int {
+	count := runtime.NumGoroutine()
+	for i := 0; i < 50 && count > want; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+		count = runtime.NumGoroutine()
+	}
+	return count
+}
+
+// 自分の本体の環境から参照できるジェネレータはファイナライザで回収されないが,Closeで本体のgoroutineが終了する.
+func TestCloseKillsSelfReferencingGenerators(t *testing.T) {
+	sources := map[string]string{
+		"local": `
+fun use() {
+  fun gen() { while (true) yield 1; }
+  var g = gen();
+  g.next();
+}
+for (var i in range(200)) use();
+`,
+		"field": `
+class Box {
+  values() { while (true) yield 1; }
+  start() {
+    this.g = this.values();
+    this.g.next();
+  }
+}
+for (var i in range(200)) Box().start();
+`,
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			interpreter := newTestInterpreter()
+			if err := interpret(t, context.Background(), interpreter, source); err != nil {
+				t.Fatal(err)
+			}
+			interpreter.Close()
+			if count := waitGoroutines(before); count > before {
+				t.Fatalf("got %d goroutines after Close, want at most %d", count, before)
+			}
+		})
+	}
+}
+
+// Closeで終了させたジェネレータは,終了したジェネレータとして扱われる.
+func TestGeneratorAfterClose(t *testing.T) {
+	interpreter := newTestInterpreter()
+	source := `
+fun gen() {
+  try {
+    while (true) yield 1;
+  } finally {
+    print "unreachable";
+  }
+}
+var g = gen();
+g.next();
+`
+	if err := interpret(t, context.Background(), interpreter, source); err != nil {
+		t.Fatal(err)
+	}
+	interpreter.Close()
+	if err := interpret(t, context.Background(), interpreter, "var more = g.hasNext(); var value = g.next(); g.close();"); err != nil {
+		t.Fatal(err)
+	}
+	if more := global(t, interpreter, "more"); more != false {
+		t.Fatalf("got hasNext() %v, want false", more)
+	}
+	if value := global(t, interpreter, "value"); value != nil {
+		t.Fatalf("got next() %v, want nil", value)
+	}
+}
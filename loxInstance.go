@@ -0,0 +1,37 @@
+package mygolox
+
+// LoxInstance はloxのクラスのインスタンスを表す構造体.java実装のloxにおけるLoxInstanceクラス.
+type LoxInstance struct {
+	klass  *LoxClass
+	fields map[string]any
+}
+
+// NewLoxInstance はLoxInstanceのコンストラクタ.
+func NewLoxInstance(klass *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		klass:  klass,
+		fields: map[string]any{},
+	}
+}
+
+func (l *LoxInstance) get(name Token) (any, error) {
+	// フィールドはメソッドよりも優先される.
+	if v, ok := l.fields[name.Lexeme]; ok {
+		return v, nil
+	}
+
+	method := l.klass.findMethod(name.Lexeme)
+	if method != nil {
+		return method.bind(l), nil
+	}
+
+	return nil, NewRuntimeError(name, "Undefined property '"+name.Lexeme+"'.")
+}
+
+func (l *LoxInstance) set(name Token, value any) {
+	l.fields[name.Lexeme] = value
+}
+
+func (l *LoxInstance) String() string {
+	return l.klass.Name + " instance"
+}
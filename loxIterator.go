@@ -0,0 +1,181 @@
+package mygolox
+
+import "fmt"
+
+// LoxRange はネイティブ関数rangeが返す数値の範囲.endは範囲に含まれない.
+// start,end,stepがすべてint64の場合は整数を,それ以外は浮動小数点数を順に返す.
+type LoxRange struct {
+	start any
+	end   any
+	step  any
+}
+
+// NewLoxRange はLoxRangeのコンストラクタ.
+func NewLoxRange(start, end, step any) *LoxRange {
+	return &LoxRange{
+		start: start,
+		end:   end,
+		step:  step,
+	}
+}
+
+func (l *LoxRange) String() string {
+	return fmt.Sprintf("range(%s, %s, %s)", stringify(l.start), stringify(l.end), stringify(l.step))
+}
+
+// loxIterator はfor-in文で値を1つずつ取り出すためのインターフェイス.
+// 値が残っていない場合はokがfalseになる.
+type loxIterator interface {
+	next() (value any, ok bool, err error)
+}
+
+type listIterator struct {
+	list  *LoxList
+	index int
+}
+
+// next はループ中に要素が追加,削除された場合も,その時点のリストの長さに従う.
+func (l *listIterator) next() (any, bool, error) {
+	if l.index >= len(l.list.Elements) {
+		return nil, false, nil
+	}
+	value := l.list.Elements[l.index]
+	l.index++
+	return value, true, nil
+}
+
+type stringIterator struct {
+	chars []rune
+	index int
+}
+
+func (s *stringIterator) next() (any, bool, error) {
+	if s.index >= len(s.chars) {
+		return nil, false, nil
+	}
+	value := string(s.chars[s.index])
+	s.index++
+	return value, true, nil
+}
+
+// rangeIterator は浮動小数点数のrangeを反復する.
+// stepを足し続けると誤差が積み重なるので,値は毎回start + index*stepで計算する.
+type rangeIterator struct {
+	start float64
+	end   float64
+	step  float64
+	index int64
+}
+
+func (r *rangeIterator) next() (any, bool, error) {
+	value := r.start + float64(r.index)*r.step
+	if (r.step > 0 && value >= r.end) || (r.step < 0 && value <= r.end) {
+		return nil, false, nil
+	}
+	r.index++
+	return value, true, nil
+}
+
+type integerRangeIterator struct {
+	current int64
+	end     int64
+	step    int64
+	done    bool
+}
+
+func (r *integerRangeIterator) next() (any, bool, error) {
+	if r.done || (r.step > 0 && r.current >= r.end) || (r.step < 0 && r.current <= r.end) {
+		return nil, false, nil
+	}
+	value := r.current
+	next := r.current + r.step
+	// int64の範囲を越える場合はそこで終わりにする.
+	if (next > r.current) != (r.step > 0) {
+		r.done = true
+	}
+	r.current = next
+	return value, true, nil
+}
+
+// iterator はrangeの値を順に返すloxIteratorを作る.
+func (l *LoxRange) iterator() loxIterator {
+	start, okStart := l.start.(int64)
+	end, okEnd := l.end.(int64)
+	step, okStep := l.step.(int64)
+	if okStart && okEnd && okStep {
+		return &integerRangeIterator{current: start, end: end, step: step}
+	}
+	return &rangeIterator{start: toFloat(l.start), end: toFloat(l.end), step: toFloat(l.step)}
+}
+
+// protocolIterator はhasNextとnextのメソッドを持つloxの値を反復する.
+type protocolIterator struct {
+	interpreter *Interpreter
+	token       Token
+	hasNext     LoxCallable
+	nextValue   LoxCallable
+}
+
+func (p *protocolIterator) next() (any, bool, error) {
+	hasNext, err := p.interpreter.callValue(p.token, p.hasNext, []any{})
+	if err != nil {
+		return nil, false, err
+	}
+	if !p.interpreter.isTruthy(hasNext) {
+		return nil, false, nil
+	}
+
+	value, err := p.interpreter.callValue(p.token, p.nextValue, []any{})
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// iterator はfor-in文で反復するための値からloxIteratorを作る.
+// リストは要素,マップはキー,文字列は1文字ずつ,rangeは数値を,ジェネレータはyieldされた値を順に返す.
+// インスタンスはiteratorメソッドがあればその戻り値を,無ければインスタンス自身を,
+// hasNextとnextのメソッドを持つイテレータとして扱う.
+func (i *Interpreter) iterator(token Token, iterable any) (loxIterator, error) {
+	switch v := iterable.(type) {
+	case *LoxList:
+		return &listIterator{list: v}, nil
+	case *LoxMap:
+		keys := make([]any, len(v.keys))
+		copy(keys, v.keys)
+		return &listIterator{list: NewLoxList(keys)}, nil
+	case string:
+		return &stringIterator{chars: []rune(v)}, nil
+	case *LoxRange:
+		return v.iterator(), nil
+	case *LoxGenerator:
+		return v, nil
+	case *LoxInstance:
+		iterator := v
+		if method, err := v.get(*NewToken(IDENTIFIER, "iterator", nil, token.Line)); err == nil {
+			value, err := i.callValue(token, method, []any{})
+			if err != nil {
+				return nil, err
+			}
+			if generator, ok := value.(*LoxGenerator); ok {
+				return generator, nil
+			}
+			instance, ok := value.(*LoxInstance)
+			if !ok {
+				return nil, NewRuntimeError(token, "Method 'iterator' must return an instance or a generator.")
+			}
+			iterator = instance
+		}
+
+		hasNext, errHasNext := iterator.get(*NewToken(IDENTIFIER, "hasNext", nil, token.Line))
+		next, errNext := iterator.get(*NewToken(IDENTIFIER, "next", nil, token.Line))
+		hasNextCallable, okHasNext := hasNext.(LoxCallable)
+		nextCallable, okNext := next.(LoxCallable)
+		if errHasNext != nil || errNext != nil || !okHasNext || !okNext {
+			return nil, NewRuntimeError(token, "Iterator must have 'hasNext' and 'next' methods.")
+		}
+		return &protocolIterator{interpreter: i, token: token, hasNext: hasNextCallable, nextValue: nextCallable}, nil
+	}
+
+	return nil, NewRuntimeError(token, "Can only iterate over lists, maps, strings, ranges, generators and iterators.")
+}
@@ -0,0 +1,85 @@
+package mygolox
+
+import "strings"
+
+// LoxList はloxのリストを表す構造体.リストは参照として共有される.
+type LoxList struct {
+	Elements []any
+}
+
+// NewLoxList はLoxListのコンストラクタ.
+func NewLoxList(elements []any) *LoxList {
+	return &LoxList{
+		Elements: elements,
+	}
+}
+
+// get はindex番目の要素を返す.bracketはランタイムエラー時の場所報告用のトークン.
+func (l *LoxList) get(bracket Token, index any) (any, error) {
+	n, err := l.checkIndex(bracket, index)
+	if err != nil {
+		return nil, err
+	}
+	return l.Elements[n], nil
+}
+
+// set はindex番目の要素をvalueで置き換える.
+func (l *LoxList) set(bracket Token, index any, value any) error {
+	n, err := l.checkIndex(bracket, index)
+	if err != nil {
+		return err
+	}
+	l.Elements[n] = value
+	return nil
+}
+
+func (l *LoxList) checkIndex(bracket Token, index any) (int, error) {
+	if _, ok := toBigInteger(index); !ok {
+		return 0, NewRuntimeError(bracket, "List index must be an integer.")
+	}
+	v, ok := toInteger(index)
+	if !ok || v < 0 || int64(len(l.Elements)) <= v {
+		return 0, NewRuntimeError(bracket, "List index out of range.")
+	}
+	return int(v), nil
+}
+
+func (l *LoxList) String() string {
+	return l.format(map[any]bool{})
+}
+
+// format はリストを表示用の文字列にする.visitingは表示中の外側のリストやマップで,
+// 自分自身を含むリストでは,再び現れた箇所を[...]と表示する.
+func (l *LoxList) format(visiting map[any]bool) string {
+	if visiting[l] {
+		return "[...]"
+	}
+	visiting[l] = true
+	defer delete(visiting, l)
+
+	var builder strings.Builder
+
+	builder.WriteString("[")
+	for i, element := range l.Elements {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(stringifyElement(element, visiting))
+	}
+	builder.WriteString("]")
+
+	return builder.String()
+}
+
+// container は要素として自分自身を含められる値.
+type container interface {
+	format(visiting map[any]bool) string
+}
+
+// stringifyElement はリストやマップの要素を表示用の文字列にする.
+func stringifyElement(element any, visiting map[any]bool) string {
+	if c, ok := element.(container); ok {
+		return c.format(visiting)
+	}
+	return stringify(element)
+}
@@ -0,0 +1,83 @@
+package mygolox
+
+import "strings"
+
+// LoxMap はloxの連想配列を表す構造体.
+// キーの比較はInterpreter.isEqualと同じ規則に従う.
+// nil,真偽値,数値,文字列は値で比較され,それ以外(リスト,マップ,インスタンス,関数など)は同一性で比較される.
+// 数値のキーは種類が異なっても値が等しければ同じキーになり,最初に挿入されたキーが保持される.
+// 表示を安定させるため,キーは挿入された順に保持する.
+type LoxMap struct {
+	keys   []any
+	values map[any]any
+}
+
+// NewLoxMap はLoxMapのコンストラクタ.
+func NewLoxMap() *LoxMap {
+	return &LoxMap{
+		keys:   []any{},
+		values: map[any]any{},
+	}
+}
+
+// get はkeyに対応する値を返す.keyが無い場合はnilを返す.
+func (l *LoxMap) get(key any) any {
+	return l.values[numberKey(key)]
+}
+
+func (l *LoxMap) set(key any, value any) {
+	if _, ok := l.values[numberKey(key)]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.values[numberKey(key)] = value
+}
+
+func (l *LoxMap) has(key any) bool {
+	_, ok := l.values[numberKey(key)]
+	return ok
+}
+
+// remove はkeyを削除し,keyが存在していたかどうかを返す.
+func (l *LoxMap) remove(key any) bool {
+	key = numberKey(key)
+	if _, ok := l.values[key]; !ok {
+		return false
+	}
+
+	delete(l.values, key)
+	for i, k := range l.keys {
+		if numberKey(k) == key {
+			l.keys = append(l.keys[:i], l.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (l *LoxMap) String() string {
+	return l.format(map[any]bool{})
+}
+
+// format はマップを表示用の文字列にする.LoxListのformatと同じく,自分自身を含むマップは{...}と表示する.
+func (l *LoxMap) format(visiting map[any]bool) string {
+	if visiting[l] {
+		return "{...}"
+	}
+	visiting[l] = true
+	defer delete(visiting, l)
+
+	var builder strings.Builder
+
+	builder.WriteString("{")
+	for i, key := range l.keys {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(stringifyElement(key, visiting))
+		builder.WriteString(": ")
+		builder.WriteString(stringifyElement(l.values[numberKey(key)], visiting))
+	}
+	builder.WriteString("}")
+
+	return builder.String()
+}
@@ -0,0 +1,110 @@
+package mygolox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoxModule はimportされたファイルを表す構造体.
+// モジュールのトップレベルで定義された名前はモジュール自身の環境に閉じ込められる.
+type LoxModule struct {
+	Name        string
+	environment *Environment
+}
+
+// NewLoxModule はLoxModuleのコンストラクタ.
+func NewLoxModule(name string, environment *Environment) *LoxModule {
+	return &LoxModule{
+		Name:        name,
+		environment: environment,
+	}
+}
+
+func (m *LoxModule) get(name Token) (any, error) {
+	if v, ok := m.environment.Values[name.Lexeme]; ok {
+		return v, nil
+	}
+
+	return nil, NewRuntimeError(name, "Module '"+m.Name+"' has no member '"+name.Lexeme+"'.")
+}
+
+func (m *LoxModule) String() string {
+	return "<module " + m.Name + ">"
+}
+
+// loadModule はpathが指すファイルをモジュールとして読み込む.
+// 一度読み込んだモジュールはキャッシュされ,二度目以降は実行されない.
+// エラーはimport文のパスのトークンを場所として報告される.
+func (i *Interpreter) loadModule(path Token) (*LoxModule, error) {
+	name := path.Literal.(string)
+	fullPath := name
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(filepath.Dir(i.ScriptPath), name)
+	}
+	fullPath, err := filepath.Abs(fullPath)
+	if err != nil {
+		return nil, NewRuntimeError(path, "Could not resolve module path '"+name+"'.")
+	}
+
+	if module, ok := i.modules[fullPath]; ok {
+		return module, nil
+	}
+	if i.loading[fullPath] {
+		return nil, NewRuntimeError(path, "Import cycle detected while importing '"+name+"'.")
+	}
+
+	bytes, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, NewRuntimeError(path, "Could not read module '"+name+"'.")
+	}
+
+	// モジュール内の構文エラーはその場で報告し,import文のランタイムエラーとして扱う.
+	diagnostics := &DiagnosticLog{}
+	sink := NewScriptSink(MultiSink{i.Diagnostics, diagnostics}, fullPath)
+	scanner := NewScanner(string(bytes), sink)
+	parser := NewParser(scanner.ScanTokens(), sink)
+	statements := parser.Parse()
+	if diagnostics.HadError() {
+		return nil, NewRuntimeError(path, "Could not compile module '"+name+"'.")
+	}
+	NewResolver(i, sink).ResolveStmts(statements)
+	if diagnostics.HadError() {
+		return nil, NewRuntimeError(path, "Could not compile module '"+name+"'.")
+	}
+
+	i.loading[fullPath] = true
+	defer delete(i.loading, fullPath)
+
+	environment := NewEnvironment().ChangeEnclosing(i.builtins)
+	previousGlobals := i.Globals
+	previousEnvironment := i.Environment
+	previousPath := i.ScriptPath
+	defer func() {
+		i.Globals = previousGlobals
+		i.Environment = previousEnvironment
+		i.ScriptPath = previousPath
+	}()
+	i.Globals = environment
+	i.Environment = environment
+	i.ScriptPath = fullPath
+
+	for _, statement := range statements {
+		completion := i.execute(statement)
+		// トレースバックはモジュールのトップレベルのフレームを加えて,import文のエラーに引き継ぐ.
+		unwind(completion.Err, CallFrame{Function: "<module " + name + ">", Script: fullPath}, path.Line)
+		if err, ok := completion.Err.(*RuntimeError); ok {
+			moduleError := NewRuntimeError(path, fmt.Sprintf("In module '%s' at line %d: %s", name, err.Token.Line, err.Message))
+			moduleError.Trace = err.Trace
+			moduleError.scriptPath, moduleError.located = previousPath, true
+			return nil, moduleError
+		}
+		if completion.Kind == ERROR_COMPLETION {
+			return nil, completion.Err
+		}
+	}
+
+	module := NewLoxModule(name, environment)
+	i.modules[fullPath] = module
+	return module, nil
+}
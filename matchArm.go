@@ -0,0 +1,41 @@
+package mygolox
+
+// PatternKind はmatch文のパターンの種類.
+type PatternKind int
+
+const (
+	// LITERAL_PATTERN は値がリテラルと等しい場合にマッチする.
+	LITERAL_PATTERN PatternKind = iota
+	// RANGE_PATTERN は値がLow以上High以下の数値の場合にマッチする.
+	RANGE_PATTERN
+	// BINDING_PATTERN は任意の値にマッチし,その値をNameの変数に束縛する.
+	BINDING_PATTERN
+)
+
+// Pattern はmatch文のcaseに書く1つのパターン.
+type Pattern struct {
+	Kind  PatternKind
+	Token Token
+	Value any
+	Low   any
+	High  any
+}
+
+// MatchArm はmatch文の1つの分岐.else節の場合はPatternsがnilになる.
+type MatchArm struct {
+	Keyword  Token
+	Patterns []Pattern
+	Guard    Expr
+	Body     Stmt
+}
+
+// bindings はarmのパターンが導入する変数の名前を返す.
+func (m *MatchArm) bindings() []Token {
+	names := make([]Token, 0)
+	for _, pattern := range m.Patterns {
+		if pattern.Kind == BINDING_PATTERN {
+			names = append(names, pattern.Token)
+		}
+	}
+	return names
+}
@@ -1,6 +1,10 @@
 package mygolox
 
-import "time"
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
 
 type clock struct {
 }
@@ -9,14 +13,204 @@ func NewClock() *clock {
 	return &clock{}
 }
 
-func (c *clock) Arity() int {
-	return 0
+func (c *clock) Arity() (int, int) {
+	return 0, 0
 }
 
-func (c *clock) Call(interpreter Interpreter, arguments []any) any {
-	return float64(time.Now().Unix())
+func (c *clock) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	return float64(time.Now().Unix()), nil
+}
+
+func (c *clock) name() string {
+	return "clock"
 }
 
 func (c *clock) String() string {
 	return "<native fn>"
 }
+
+type sleep struct {
+}
+
+func NewSleep() *sleep {
+	return &sleep{}
+}
+
+func (s *sleep) Arity() (int, int) {
+	return 1, 1
+}
+
+// Call は引数の秒数だけ待つ.待っている間に実行中のcontextが終了した場合は,そこで実行を打ち切る.
+func (s *sleep) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	if !isNumber(arguments[0]) || toFloat(arguments[0]) < 0 {
+		return nil, errors.New("Argument to 'sleep' must be a non-negative number.")
+	}
+	seconds := toFloat(arguments[0])
+	timer := time.NewTimer(time.Duration(seconds * float64(time.Second)))
+	defer timer.Stop()
+	ctx := interpreter.Context()
+	select {
+	case <-timer.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, interpreter.executionBudget().interrupted(ctx.Err())
+	}
+}
+
+func (s *sleep) name() string {
+	return "sleep"
+}
+
+func (s *sleep) String() string {
+	return "<native fn>"
+}
+
+type length struct {
+}
+
+func NewLen() *length {
+	return &length{}
+}
+
+func (l *length) Arity() (int, int) {
+	return 1, 1
+}
+
+func (l *length) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case *LoxList:
+		return int64(len(v.Elements)), nil
+	case *LoxMap:
+		return int64(len(v.keys)), nil
+	case string:
+		return int64(utf8.RuneCountInString(v)), nil
+	}
+	return nil, errors.New("Argument to 'len' must be a list, a map or a string.")
+}
+
+func (l *length) name() string {
+	return "len"
+}
+
+func (l *length) String() string {
+	return "<native fn>"
+}
+
+type has struct {
+}
+
+func NewHas() *has {
+	return &has{}
+}
+
+func (h *has) Arity() (int, int) {
+	return 2, 2
+}
+
+func (h *has) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	if m, ok := arguments[0].(*LoxMap); ok {
+		return m.has(arguments[1]), nil
+	}
+	return nil, errors.New("First argument to 'has' must be a map.")
+}
+
+func (h *has) name() string {
+	return "has"
+}
+
+func (h *has) String() string {
+	return "<native fn>"
+}
+
+type deleteKey struct {
+}
+
+func NewDelete() *deleteKey {
+	return &deleteKey{}
+}
+
+func (d *deleteKey) Arity() (int, int) {
+	return 2, 2
+}
+
+func (d *deleteKey) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	if m, ok := arguments[0].(*LoxMap); ok {
+		return m.remove(arguments[1]), nil
+	}
+	return nil, errors.New("First argument to 'delete' must be a map.")
+}
+
+func (d *deleteKey) name() string {
+	return "delete"
+}
+
+func (d *deleteKey) String() string {
+	return "<native fn>"
+}
+
+type keys struct {
+}
+
+func NewKeys() *keys {
+	return &keys{}
+}
+
+func (k *keys) Arity() (int, int) {
+	return 1, 1
+}
+
+func (k *keys) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	if m, ok := arguments[0].(*LoxMap); ok {
+		elements := make([]any, len(m.keys))
+		copy(elements, m.keys)
+		return NewLoxList(elements), nil
+	}
+	return nil, errors.New("Argument to 'keys' must be a map.")
+}
+
+func (k *keys) name() string {
+	return "keys"
+}
+
+func (k *keys) String() string {
+	return "<native fn>"
+}
+
+type rangeFunc struct {
+}
+
+func NewRange() *rangeFunc {
+	return &rangeFunc{}
+}
+
+func (r *rangeFunc) Arity() (int, int) {
+	return 1, 3
+}
+
+// Call はrange(end),range(start, end),range(start, end, step)の形で呼び出される.
+// startの既定値は0,stepの既定値は1.
+func (r *rangeFunc) Call(interpreter *Interpreter, arguments []any) (any, error) {
+	numbers := []any{int64(0), int64(0), int64(1)}
+	for i, argument := range arguments {
+		if !isNumber(argument) {
+			return nil, errors.New("Arguments to 'range' must be numbers.")
+		}
+		numbers[i] = argument
+	}
+	if len(arguments) == 1 {
+		numbers[0], numbers[1] = int64(0), numbers[0]
+	}
+	start, end, step := numbers[0], numbers[1], numbers[2]
+	if c, ok := compareNumbers(step, int64(0)); !ok || c == 0 {
+		return nil, errors.New("Range step must not be zero.")
+	}
+	return NewLoxRange(start, end, step), nil
+}
+
+func (r *rangeFunc) name() string {
+	return "range"
+}
+
+func (r *rangeFunc) String() string {
+	return "<native fn>"
+}
@@ -0,0 +1,237 @@
+package mygolox
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// loxの数値はint64,*big.Int,float64のいずれかで表す.
+// 小数点の無いリテラルは整数になり,*big.Intはint64に収まらない整数にだけ使う.
+// 整数どうしの演算はint64で行い,オーバーフローする場合は*big.Intで計算する.
+// 結果がint64に収まる場合はint64に戻すので,同じ整数が2通りの表現を持つことはない.
+// 整数と浮動小数点数の演算は浮動小数点数で行う.
+
+func isNumber(object any) bool {
+	switch object.(type) {
+	case int64, *big.Int, float64:
+		return true
+	}
+	return false
+}
+
+// toBig は整数をbig.Intに変換する.
+func toBig(object any) *big.Int {
+	switch v := object.(type) {
+	case int64:
+		return big.NewInt(v)
+	case *big.Int:
+		return v
+	}
+	return nil
+}
+
+// toFloat は数値をfloat64に変換する.
+func toFloat(object any) float64 {
+	switch v := object.(type) {
+	case int64:
+		return float64(v)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f
+	case float64:
+		return v
+	}
+	return math.NaN()
+}
+
+// normalizeBig はint64に収まる整数をint64に戻す.
+func normalizeBig(v *big.Int) any {
+	if v.IsInt64() {
+		return v.Int64()
+	}
+	return v
+}
+
+// toBigInteger は整数と,整数値を持つ浮動小数点数をbig.Intに変換する.
+func toBigInteger(object any) (*big.Int, bool) {
+	switch v := object.(type) {
+	case int64, *big.Int:
+		return toBig(v), true
+	case float64:
+		if math.IsInf(v, 0) || v != math.Trunc(v) {
+			return nil, false
+		}
+		i, _ := big.NewFloat(v).Int(nil)
+		return i, true
+	}
+	return nil, false
+}
+
+// compareNumbers は2つの数値を比較して-1,0,1を返す.整数と浮動小数点数も正確に比較する.
+// どちらかがNaNの場合はokがfalseになる.
+func compareNumbers(a, b any) (int, bool) {
+	if va, ok := a.(int64); ok {
+		if vb, ok := b.(int64); ok {
+			switch {
+			case va < vb:
+				return -1, true
+			case va > vb:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	_, floatA := a.(float64)
+	_, floatB := b.(float64)
+	if !floatA && !floatB {
+		return toBig(a).Cmp(toBig(b)), true
+	}
+
+	fa, fb := toFloat(a), toFloat(b)
+	if math.IsNaN(fa) || math.IsNaN(fb) {
+		return 0, false
+	}
+	if math.IsInf(fa, 0) || math.IsInf(fb, 0) {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	return exactFloat(a).Cmp(exactFloat(b)), true
+}
+
+func exactFloat(object any) *big.Float {
+	switch v := object.(type) {
+	case int64:
+		return new(big.Float).SetInt64(v)
+	case *big.Int:
+		return new(big.Float).SetInt(v)
+	}
+	return big.NewFloat(object.(float64))
+}
+
+// numberKey はマップのキーとして使うために数値を正規化する.
+// 等しい数値(ex: 1と1.0)は同じキーになる.
+func numberKey(object any) any {
+	switch v := object.(type) {
+	case *big.Int:
+		return bigKey(v.String())
+	case float64:
+		if v == 0 {
+			return int64(0)
+		}
+		if i, ok := toBigInteger(v); ok {
+			return numberKey(normalizeBig(i))
+		}
+	}
+	return object
+}
+
+// bigKey はマップのキーとして使うint64に収まらない整数.
+type bigKey string
+
+// negateNumber は数値の符号を反転する.
+func negateNumber(object any) any {
+	switch v := object.(type) {
+	case int64:
+		if v == math.MinInt64 {
+			return new(big.Int).Neg(big.NewInt(v))
+		}
+		return -v
+	case *big.Int:
+		return normalizeBig(new(big.Int).Neg(v))
+	case float64:
+		return -v
+	}
+	return nil
+}
+
+// formatNumber は数値を表示用の文字列に変換する.整数は桁を省略せずに表示する.
+func formatNumber(object any) string {
+	switch v := object.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *big.Int:
+		return v.String()
+	}
+	return strconv.FormatFloat(object.(float64), 'g', -1, 64)
+}
+
+// parseInteger は小数点の無い数値リテラルを整数に変換する.
+func parseInteger(text string) any {
+	if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return v
+	}
+	v, _ := new(big.Int).SetString(text, 10)
+	return v
+}
+
+// numberOperation は整数どうし,それ以外の数値の組み合わせのそれぞれで使う演算.
+// integerがnilの場合は整数どうしでもfloatで計算する.
+// integerはint64で計算できない場合にokをfalseにし,そのときはbigで計算する.
+type numberOperation struct {
+	integer func(a, b int64) (any, bool)
+	big     func(a, b *big.Int) any
+	float   func(a, b float64) any
+}
+
+func (n numberOperation) apply(left, right any) any {
+	_, floatL := left.(float64)
+	_, floatR := right.(float64)
+	if floatL || floatR || n.integer == nil {
+		return n.float(toFloat(left), toFloat(right))
+	}
+
+	if vl, ok := left.(int64); ok {
+		if vr, ok := right.(int64); ok {
+			if value, ok := n.integer(vl, vr); ok {
+				return value
+			}
+		}
+	}
+	value := n.big(toBig(left), toBig(right))
+	if v, ok := value.(*big.Int); ok {
+		return normalizeBig(v)
+	}
+	return value
+}
+
+var addition = numberOperation{
+	integer: func(a, b int64) (any, bool) {
+		c := a + b
+		return c, (c > a) == (b > 0)
+	},
+	big:   func(a, b *big.Int) any { return new(big.Int).Add(a, b) },
+	float: func(a, b float64) any { return a + b },
+}
+
+var subtraction = numberOperation{
+	integer: func(a, b int64) (any, bool) {
+		c := a - b
+		return c, (c < a) == (b > 0)
+	},
+	big:   func(a, b *big.Int) any { return new(big.Int).Sub(a, b) },
+	float: func(a, b float64) any { return a - b },
+}
+
+var multiplication = numberOperation{
+	integer: func(a, b int64) (any, bool) {
+		if a == 0 || b == 0 {
+			return int64(0), true
+		}
+		c := a * b
+		return c, c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+	},
+	big:   func(a, b *big.Int) any { return new(big.Int).Mul(a, b) },
+	float: func(a, b float64) any { return a * b },
+}
+
+// division は整数どうしでも常に浮動小数点数を返す.
+var division = numberOperation{
+	float: func(a, b float64) any { return a / b },
+}
@@ -0,0 +1,55 @@
+package mygolox
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 結果が大きくなりすぎる整数の演算は,計算する前に捕まえられるランタイムエラーになる.
+func TestIntegerSizeLimit(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"var x = 3 ** 30000000;", "Result of '**' is too large."},
+		{"var x = 1 << 2000000;", "Shift count is too large."},
+		{"var x = 1 << 1048576; x = x * x;", "Result of '*' is too large."},
+		{"var x = 1 << 1048576; for (var i in range(11)) x = x * x;", "Result of '*' is too large."},
+		{"var x = 1 << 600000; x *= x;", "Result of '*' is too large."},
+	}
+	for _, test := range tests {
+		interpreter := newTestInterpreter()
+		source := "var message; try { " + test.source + " } catch (e) { message = e.message; }"
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := interpret(t, ctx, interpreter, source)
+		cancel()
+		if err != nil {
+			t.Errorf("%s: got %v, want nil", test.source, err)
+			continue
+		}
+		if got := global(t, interpreter, "message"); got != test.want {
+			t.Errorf("%s: got %v, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+// 上限に収まる演算や,結果が大きくならない演算は計算できる.
+func TestIntegerSizeWithinLimit(t *testing.T) {
+	source := `
+var big = 1 << 1048575;
+var one = (big * 1) == big;
+var zero = big * 0;
+var half = (1 << 500000) * (1 << 500000) == 1 << 1000000;
+var power = 2 ** 500000 == 1 << 500000;
+`
+	interpreter := newTestInterpreter()
+	if err := interpret(t, context.Background(), interpreter, source); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]any{"one": true, "zero": int64(0), "half": true, "power": true} {
+		if got := global(t, interpreter, name); got != want {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
@@ -4,13 +4,18 @@ package mygolox
 type Parser struct {
 	tokens  []Token
 	current int
+	// yields は構文解析中の入れ子になった関数ごとの,本体にyield文が現れたかどうか.
+	yields []bool
+	// diagnostics は見つけたエラーの報告先.
+	diagnostics DiagnosticSink
 }
 
-// NewParser はParserのコンストラクタ.
-func NewParser(tokens []Token) *Parser {
+// NewParser はParserのコンストラクタ.diagnosticsがnilの場合,エラーは捨てられる.
+func NewParser(tokens []Token, diagnostics DiagnosticSink) *Parser {
 	return &Parser{
-		tokens:  tokens,
-		current: 0,
+		tokens:      tokens,
+		current:     0,
+		diagnostics: orDiscard(diagnostics),
 	}
 }
 
@@ -28,10 +33,18 @@ func (p *Parser) declaration() Stmt {
 	var stmt Stmt
 	var ok bool
 	switch {
-	case p.match(FUN):
+	case p.match(CLASS):
+		stmt, ok = p.classDeclaration()
+	case p.check(FUN) && p.checkNext(IDENTIFIER):
+		// funの直後に名前が無い場合は無名関数の式文として扱う.
+		p.advance()
 		stmt, ok = p.function("function")
+	case p.match(IMPORT):
+		stmt, ok = p.importDeclaration()
 	case p.match(VAR):
 		stmt, ok = p.varDeclaration()
+	case p.match(CONST):
+		stmt, ok = p.constDeclaration()
 	default:
 		stmt, ok = p.statement()
 	}
@@ -41,18 +54,114 @@ func (p *Parser) declaration() Stmt {
 	return stmt
 }
 
+func (p *Parser) classDeclaration() (Stmt, bool) {
+	name, ok := p.consume(IDENTIFIER, "Expect class name.")
+	if !ok {
+		return nil, false
+	}
+
+	var superclass *Variable
+	if p.match(LESS) {
+		_, ok = p.consume(IDENTIFIER, "Expect superclass name.")
+		if !ok {
+			return nil, false
+		}
+		superclass = NewVariable(*p.previous())
+	}
+
+	_, ok = p.consume(LEFT_BRACE, "Expect '{' before class body.")
+	if !ok {
+		return nil, false
+	}
+
+	methods := make([]*Function, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		method, ok := p.function("method")
+		if !ok {
+			return nil, false
+		}
+		methods = append(methods, method)
+	}
+
+	_, ok = p.consume(RIGHT_BRACE, "Expect '}' after class body.")
+	if !ok {
+		return nil, false
+	}
+
+	return NewClass(*name, superclass, methods), true
+}
+
+// importDeclaration はimport文を構文解析する.次の3つの形がある.
+//
+//	import "path";
+//	import "path" as name;
+//	import { a, b } from "path";
+func (p *Parser) importDeclaration() (Stmt, bool) {
+	keyword := p.previous()
+
+	var names []Token
+	if p.match(LEFT_BRACE) {
+		for con := true; con; con = p.match(COMMA) {
+			name, ok := p.consume(IDENTIFIER, "Expect name to import.")
+			if !ok {
+				return nil, false
+			}
+			names = append(names, *name)
+		}
+		_, ok := p.consume(RIGHT_BRACE, "Expect '}' after imported names.")
+		if !ok {
+			return nil, false
+		}
+		if !p.matchWord("from") {
+			p.reportError(p.peek(), "Expect 'from' after imported names.")
+			return nil, false
+		}
+	}
+
+	path, ok := p.consume(STRING, "Expect module path.")
+	if !ok {
+		return nil, false
+	}
+
+	var alias *Token
+	if names == nil && p.matchWord("as") {
+		alias, ok = p.consume(IDENTIFIER, "Expect module name after 'as'.")
+		if !ok {
+			return nil, false
+		}
+	}
+
+	_, ok = p.consume(SEMICOLON, "Expect ';' after import.")
+	if !ok {
+		return nil, false
+	}
+	return NewImport(*keyword, *path, alias, names), true
+}
+
 func (p *Parser) statement() (Stmt, bool) {
 	switch {
+	case p.match(BREAK):
+		return p.breakStatement()
+	case p.match(CONTINUE):
+		return p.continueStatement()
 	case p.match(FOR):
 		return p.forStatement()
 	case p.match(IF):
 		return p.ifStatement()
+	case p.match(MATCH):
+		return p.matchStatement()
 	case p.match(PRINT):
 		return p.printStatement()
 	case p.match(RETURN):
 		return p.returnStatement()
+	case p.match(THROW):
+		return p.throwStatement()
+	case p.match(TRY):
+		return p.tryStatement()
 	case p.match(WHILE):
 		return p.whileStatement()
+	case p.match(YIELD):
+		return p.yieldStatement()
 	case p.match(LEFT_BRACE):
 		block, ok := p.block()
 		if !ok {
@@ -64,12 +173,34 @@ func (p *Parser) statement() (Stmt, bool) {
 	return p.expressionStatement()
 }
 
+func (p *Parser) breakStatement() (Stmt, bool) {
+	keyword := p.previous()
+	_, ok := p.consume(SEMICOLON, "Expect ';' after 'break'.")
+	if !ok {
+		return nil, false
+	}
+	return NewBreak(*keyword), true
+}
+
+func (p *Parser) continueStatement() (Stmt, bool) {
+	keyword := p.previous()
+	_, ok := p.consume(SEMICOLON, "Expect ';' after 'continue'.")
+	if !ok {
+		return nil, false
+	}
+	return NewContinue(*keyword), true
+}
+
 func (p *Parser) forStatement() (Stmt, bool) {
 	_, ok := p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
 	if !ok {
 		return nil, false
 	}
 
+	if p.check(VAR) && p.checkNext(IDENTIFIER) && p.checkAhead(2, IN) {
+		return p.forInStatement()
+	}
+
 	var initializer Stmt
 	if p.match(SEMICOLON) {
 		initializer = nil
@@ -114,11 +245,8 @@ func (p *Parser) forStatement() (Stmt, bool) {
 		return nil, false
 	}
 
-	if increment != nil {
-		body = NewBlock([]Stmt{body, NewExpress(increment)})
-	}
-
-	body = NewWhile(condition, body)
+	// continueでもインクリメントが実行されるように,インクリメントは本体に含めずWhileに持たせる.
+	body = NewWhile(condition, body, increment)
 
 	if initializer != nil {
 		body = NewBlock([]Stmt{initializer, body})
@@ -127,6 +255,29 @@ func (p *Parser) forStatement() (Stmt, bool) {
 	return body, true
 }
 
+// forInStatement は'for ('の後に続く'var name in iterable) body'を構文解析する.
+func (p *Parser) forInStatement() (Stmt, bool) {
+	p.advance()
+	name := p.advance()
+	in := p.advance()
+
+	iterable, ok := p.expression()
+	if !ok {
+		return nil, false
+	}
+	_, ok = p.consume(RIGHT_PAREN, "Expect ')' after for-in clause.")
+	if !ok {
+		return nil, false
+	}
+
+	body, ok := p.statement()
+	if !ok {
+		return nil, false
+	}
+
+	return NewForIn(*name, *in, iterable, body), true
+}
+
 func (p *Parser) ifStatement() (Stmt, bool) {
 	_, ok := p.consume(LEFT_PAREN, "Expect '(' after 'if'.")
 	if !ok {
@@ -155,7 +306,135 @@ func (p *Parser) ifStatement() (Stmt, bool) {
 	return NewIf(condition, thenBranch, elseBranch), true
 }
 
+// matchStatement はmatch文を構文解析する.
+//
+//	match (value) {
+//	  case 1, 2 => stmt
+//	  case 3..5 => stmt
+//	  case x if x > 10 => stmt
+//	  else => stmt
+//	}
+func (p *Parser) matchStatement() (Stmt, bool) {
+	keyword := p.previous()
+	_, ok := p.consume(LEFT_PAREN, "Expect '(' after 'match'.")
+	if !ok {
+		return nil, false
+	}
+	subject, ok := p.expression()
+	if !ok {
+		return nil, false
+	}
+	_, ok = p.consume(RIGHT_PAREN, "Expect ')' after match value.")
+	if !ok {
+		return nil, false
+	}
+	_, ok = p.consume(LEFT_BRACE, "Expect '{' before match arms.")
+	if !ok {
+		return nil, false
+	}
+
+	arms := make([]*MatchArm, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		armKeyword := p.peek()
+		var patterns []Pattern
+		if !p.match(ELSE) {
+			_, ok = p.consume(CASE, "Expect 'case' or 'else' in match body.")
+			if !ok {
+				return nil, false
+			}
+			patterns = make([]Pattern, 0)
+			for con := true; con; con = p.match(COMMA) {
+				pattern, ok := p.pattern()
+				if !ok {
+					return nil, false
+				}
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		var guard Expr
+		if patterns != nil && p.match(IF) {
+			guard, ok = p.expression()
+			if !ok {
+				return nil, false
+			}
+		}
+
+		_, ok = p.consume(ARROW, "Expect '=>' after pattern.")
+		if !ok {
+			return nil, false
+		}
+		body, ok := p.statement()
+		if !ok {
+			return nil, false
+		}
+		arms = append(arms, &MatchArm{Keyword: *armKeyword, Patterns: patterns, Guard: guard, Body: body})
+
+		if patterns == nil && !p.check(RIGHT_BRACE) {
+			p.reportError(p.peek(), "The 'else' arm must be the last arm.")
+			return nil, false
+		}
+	}
+
+	_, ok = p.consume(RIGHT_BRACE, "Expect '}' after match arms.")
+	if !ok {
+		return nil, false
+	}
+	return NewMatch(*keyword, subject, arms), true
+}
+
+// pattern はcaseに書くパターンを1つ構文解析する.
+// パターンはリテラル,数値の範囲(下限..上限,両端を含む),変数の束縛のいずれか.
+func (p *Parser) pattern() (Pattern, bool) {
+	if p.match(IDENTIFIER) {
+		return Pattern{Kind: BINDING_PATTERN, Token: *p.previous()}, true
+	}
+
+	token := p.peek()
+	value, ok := p.patternLiteral()
+	if !ok {
+		return Pattern{}, false
+	}
+
+	if p.match(DOT_DOT) {
+		high, ok := p.patternLiteral()
+		if !ok {
+			return Pattern{}, false
+		}
+		if !isNumber(value) || !isNumber(high) {
+			p.reportError(token, "Range pattern bounds must be numbers.")
+			return Pattern{}, false
+		}
+		return Pattern{Kind: RANGE_PATTERN, Token: *token, Low: value, High: high}, true
+	}
+
+	return Pattern{Kind: LITERAL_PATTERN, Token: *token, Value: value}, true
+}
+
+func (p *Parser) patternLiteral() (any, bool) {
+	switch {
+	case p.match(FALSE):
+		return false, true
+	case p.match(TRUE):
+		return true, true
+	case p.match(NIL):
+		return nil, true
+	case p.match(NUMBER, STRING):
+		return p.previous().Literal, true
+	case p.match(MINUS):
+		number, ok := p.consume(NUMBER, "Expect number after '-' in pattern.")
+		if !ok {
+			return nil, false
+		}
+		return negateNumber(number.Literal), true
+	}
+
+	p.reportError(p.peek(), "Expect pattern.")
+	return nil, false
+}
+
 func (p *Parser) varDeclaration() (Stmt, bool) {
+	doc := p.previous().Doc
 	name, ok := p.consume(IDENTIFIER, "Expect variable name.")
 	if !ok {
 		return nil, false
@@ -173,7 +452,31 @@ func (p *Parser) varDeclaration() (Stmt, bool) {
 	if !ok {
 		return nil, false
 	}
-	return NewVar(*name, initializer), true
+	return NewVar(*name, initializer, false, doc), true
+}
+
+func (p *Parser) constDeclaration() (Stmt, bool) {
+	doc := p.previous().Doc
+	name, ok := p.consume(IDENTIFIER, "Expect constant name.")
+	if !ok {
+		return nil, false
+	}
+
+	// 定数は後から代入できないので初期化子が必須.
+	_, ok = p.consume(EQUAL, "Expect '=' after constant name.")
+	if !ok {
+		return nil, false
+	}
+	initializer, ok := p.expression()
+	if !ok {
+		return nil, false
+	}
+
+	_, ok = p.consume(SEMICOLON, "Expect ';' after expression.")
+	if !ok {
+		return nil, false
+	}
+	return NewVar(*name, initializer, true, doc), true
 }
 
 func (p *Parser) whileStatement() (Stmt, bool) {
@@ -195,7 +498,7 @@ func (p *Parser) whileStatement() (Stmt, bool) {
 		return nil, false
 	}
 
-	return NewWhile(condition, body), true
+	return NewWhile(condition, body, nil), true
 }
 
 func (p *Parser) printStatement() (Stmt, bool) {
@@ -228,6 +531,98 @@ func (p *Parser) returnStatement() (Stmt, bool) {
 	return NewReturn(*keyword, value), true
 }
 
+// yieldStatement はyield文を構文解析する.値を省略した場合はnilをyieldする.
+// yield文を含む関数はジェネレータになる.
+func (p *Parser) yieldStatement() (Stmt, bool) {
+	keyword := p.previous()
+	var value Expr = NewLiteral(nil)
+	if !p.check(SEMICOLON) {
+		var ok bool
+		value, ok = p.expression()
+		if !ok {
+			return nil, false
+		}
+	}
+	_, ok := p.consume(SEMICOLON, "Expect ';' after yield value.")
+	if !ok {
+		return nil, false
+	}
+	if len(p.yields) > 0 {
+		p.yields[len(p.yields)-1] = true
+	}
+	return NewYield(*keyword, value), true
+}
+
+func (p *Parser) throwStatement() (Stmt, bool) {
+	keyword := p.previous()
+	value, ok := p.expression()
+	if !ok {
+		return nil, false
+	}
+
+	_, ok = p.consume(SEMICOLON, "Expect ';' after thrown value.")
+	if !ok {
+		return nil, false
+	}
+	return NewThrow(*keyword, value), true
+}
+
+func (p *Parser) tryStatement() (Stmt, bool) {
+	keyword := p.previous()
+	_, ok := p.consume(LEFT_BRACE, "Expect '{' after 'try'.")
+	if !ok {
+		return nil, false
+	}
+	body, ok := p.block()
+	if !ok {
+		return nil, false
+	}
+
+	var catchParam *Token
+	var catchBody []Stmt
+	if p.match(CATCH) {
+		_, ok = p.consume(LEFT_PAREN, "Expect '(' after 'catch'.")
+		if !ok {
+			return nil, false
+		}
+		catchParam, ok = p.consume(IDENTIFIER, "Expect exception variable name.")
+		if !ok {
+			return nil, false
+		}
+		_, ok = p.consume(RIGHT_PAREN, "Expect ')' after exception variable name.")
+		if !ok {
+			return nil, false
+		}
+		_, ok = p.consume(LEFT_BRACE, "Expect '{' before catch body.")
+		if !ok {
+			return nil, false
+		}
+		catchBody, ok = p.block()
+		if !ok {
+			return nil, false
+		}
+	}
+
+	var finallyBody []Stmt
+	if p.match(FINALLY) {
+		_, ok = p.consume(LEFT_BRACE, "Expect '{' after 'finally'.")
+		if !ok {
+			return nil, false
+		}
+		finallyBody, ok = p.block()
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if catchParam == nil && finallyBody == nil {
+		p.reportError(keyword, "Expect 'catch' or 'finally' after try block.")
+		return nil, false
+	}
+
+	return NewTry(body, catchParam, catchBody, finallyBody), true
+}
+
 func (p *Parser) expressionStatement() (Stmt, bool) {
 	expr, ok := p.expression()
 	if !ok {
@@ -237,44 +632,130 @@ func (p *Parser) expressionStatement() (Stmt, bool) {
 	if !ok {
 		return nil, false
 	}
-	return NewExpress(expr), true
-}
+	return NewExpress(expr), true
+}
+
+func (p *Parser) function(kind string) (*Function, bool) {
+	// ドキュメントコメントは宣言の最初のトークンに付いている.関数ならfun,メソッドなら名前.
+	doc := p.previous().Doc
+	name, ok := p.consume(IDENTIFIER, "Expect "+kind+" name.")
+	if !ok {
+		return nil, false
+	}
+	if kind == "method" {
+		doc = name.Doc
+	}
+	_, ok = p.consume(LEFT_PAREN, "Expect '(' after "+kind+" name.")
+	if !ok {
+		return nil, false
+	}
+	parameters, defaults, rest, ok := p.parameters()
+	if !ok {
+		return nil, false
+	}
+	_, ok = p.consume(LEFT_BRACE, "Expect '{' before "+kind+" body.")
+	if !ok {
+		return nil, false
+	}
+	body, generator, ok := p.functionBody()
+	if !ok {
+		return nil, false
+	}
+	return NewFunction(*name, parameters, defaults, rest, body, generator, doc), true
+}
+
+// functionBody は'{'の後から関数の本体を構文解析し,本体がyield文を含むかどうかも返す.
+// 入れ子になった関数の中のyield文は,外側の関数をジェネレータにしない.
+func (p *Parser) functionBody() ([]Stmt, bool, bool) {
+	p.yields = append(p.yields, false)
+	body, ok := p.block()
+	generator := p.yields[len(p.yields)-1]
+	p.yields = p.yields[:len(p.yields)-1]
+	return body, generator, ok
+}
+
+// lambda は無名関数を構文解析する.本体はブロックか,'=>'に続く1つの式のどちらか.
+// 無名関数のFunctionのNameには,名前の代わりにfunキーワードのトークンを入れる.
+func (p *Parser) lambda() (Expr, bool) {
+	keyword := p.previous()
+	_, ok := p.consume(LEFT_PAREN, "Expect '(' after 'fun'.")
+	if !ok {
+		return nil, false
+	}
+	parameters, defaults, rest, ok := p.parameters()
+	if !ok {
+		return nil, false
+	}
 
-func (p *Parser) function(kind string) (*Function, bool) {
-	name, ok := p.consume(IDENTIFIER, "Expect "+kind+" name.")
+	if p.match(ARROW) {
+		arrow := p.previous()
+		value, ok := p.expression()
+		if !ok {
+			return nil, false
+		}
+		body := []Stmt{NewReturn(*arrow, value)}
+		return NewLambda(NewFunction(*keyword, parameters, defaults, rest, body, false, "")), true
+	}
+
+	_, ok = p.consume(LEFT_BRACE, "Expect '{' before function body.")
 	if !ok {
 		return nil, false
 	}
-	_, ok = p.consume(LEFT_PAREN, "Expect '(' after "+kind+" name.")
+	body, generator, ok := p.functionBody()
 	if !ok {
 		return nil, false
 	}
-	parameters := make([]Token, 0)
+	return NewLambda(NewFunction(*keyword, parameters, defaults, rest, body, generator, "")), true
+}
+
+// parameters は'('の後から')'までの仮引数の並びを構文解析する.
+// defaultsはparametersと同じ長さで,デフォルト値の無い仮引数に対応する要素はnilになる.
+// 残余引数(...name)は最後にだけ書くことができ,無い場合restはnilになる.
+func (p *Parser) parameters() (parameters []Token, defaults []Expr, rest *Token, ok bool) {
+	parameters = make([]Token, 0)
+	defaults = make([]Expr, 0)
 	if !p.check(RIGHT_PAREN) {
 		for con := true; con; con = p.match(COMMA) {
 			if len(parameters) >= 255 {
-				parserResolverError(p.peek(), "Can't have more than 255 parameters.")
+				p.reportError(p.peek(), "Can't have more than 255 parameters.")
 			}
+
+			if p.match(DOT_DOT_DOT) {
+				rest, ok = p.consume(IDENTIFIER, "Expect parameter name after '...'.")
+				if !ok {
+					return nil, nil, nil, false
+				}
+				if p.check(COMMA) {
+					p.reportError(p.peek(), "Rest parameter must be last.")
+					return nil, nil, nil, false
+				}
+				break
+			}
+
 			param, ok := p.consume(IDENTIFIER, "Expect parameter name.")
 			if !ok {
-				return nil, false
+				return nil, nil, nil, false
 			}
+
+			var defaultValue Expr
+			if p.match(EQUAL) {
+				defaultValue, ok = p.expression()
+				if !ok {
+					return nil, nil, nil, false
+				}
+			} else if len(defaults) > 0 && defaults[len(defaults)-1] != nil {
+				p.reportError(param, "Parameter without default value can't follow one with a default value.")
+			}
+
 			parameters = append(parameters, *param)
+			defaults = append(defaults, defaultValue)
 		}
 	}
 	_, ok = p.consume(RIGHT_PAREN, "Expect ')' after parameters.")
 	if !ok {
-		return nil, false
-	}
-	_, ok = p.consume(LEFT_BRACE, "Expect '{' before "+kind+" body.")
-	if !ok {
-		return nil, false
-	}
-	body, ok := p.block()
-	if !ok {
-		return nil, false
+		return nil, nil, nil, false
 	}
-	return NewFunction(*name, parameters, body), true
+	return parameters, defaults, rest, true
 }
 
 func (p *Parser) block() ([]Stmt, bool) {
@@ -292,12 +773,31 @@ func (p *Parser) block() ([]Stmt, bool) {
 	return statements, true
 }
 
+// expression は式を構文解析する.演算子の優先順位は低いものから次の通り.
+//
+//	= += -= *= /=             assignment (右結合)
+//	?:                        conditional (右結合)
+//	??                        nullCoalesce
+//	or                        or
+//	and                       and
+//	== !=                     equality
+//	< <= > >=                 comparison
+//	|                         bitOr
+//	^                         bitXor
+//	&                         bitAnd
+//	<< >>                     shift
+//	+ -                       term
+//	* / ~/ %                  factor
+//	! - ~ ++ --               unary (前置)
+//	**                        power (右結合)
+//	++ --                     postfix (後置)
+//	() . [] ?.() ?.           call
 func (p *Parser) expression() (Expr, bool) {
 	return p.assignment()
 }
 
 func (p *Parser) assignment() (Expr, bool) {
-	expr, ok := p.or()
+	expr, ok := p.conditional()
 	if !ok {
 		return nil, false
 	}
@@ -309,11 +809,86 @@ func (p *Parser) assignment() (Expr, bool) {
 			return nil, false
 		}
 
-		if v, ok := expr.(*Variable); ok {
+		switch v := expr.(type) {
+		case *Variable:
 			return NewAssign(v.Name, value), true
+		case *Get:
+			if !v.Optional {
+				return NewSet(v.Object, v.Name, value), true
+			}
+		case *Subscript:
+			return NewSetSubscript(v.Object, v.Bracket, v.Index, value), true
+		}
+
+		p.reportError(equals, "Invalid assignment target.")
+	} else if p.match(PLUS_EQUAL, MINUS_EQUAL, STAR_EQUAL, SLASH_EQUAL) {
+		operator := p.previous()
+		value, ok := p.assignment()
+		if !ok {
+			return nil, false
+		}
+
+		if p.isAssignable(expr) {
+			return NewCompoundAssign(expr, *operator, value, false), true
+		}
+
+		p.reportError(operator, "Invalid assignment target.")
+	}
+
+	return expr, true
+}
+
+// isAssignable はexprが代入先として使える式かどうかを返す.
+func (p *Parser) isAssignable(expr Expr) bool {
+	switch v := expr.(type) {
+	case *Variable, *Subscript:
+		return true
+	case *Get:
+		return !v.Optional
+	}
+	return false
+}
+
+// conditional は三項演算子を構文解析する.右結合で,then節には代入を含む任意の式を書ける.
+func (p *Parser) conditional() (Expr, bool) {
+	expr, ok := p.nullCoalesce()
+	if !ok {
+		return nil, false
+	}
+
+	if p.match(QUESTION) {
+		thenBranch, ok := p.expression()
+		if !ok {
+			return nil, false
+		}
+		_, ok = p.consume(COLON, "Expect ':' after then branch of conditional expression.")
+		if !ok {
+			return nil, false
+		}
+		elseBranch, ok := p.conditional()
+		if !ok {
+			return nil, false
 		}
+		expr = NewConditional(expr, thenBranch, elseBranch)
+	}
+
+	return expr, true
+}
 
-		parserResolverError(equals, "Invalid assignment target.")
+// nullCoalesce は??演算子を構文解析する.右辺は左辺がnilの場合にだけ評価されるのでLogicalとして表す.
+func (p *Parser) nullCoalesce() (Expr, bool) {
+	expr, ok := p.or()
+	if !ok {
+		return nil, false
+	}
+
+	for p.match(QUESTION_QUESTION) {
+		operator := *p.previous()
+		right, ok := p.or()
+		if !ok {
+			return nil, false
+		}
+		expr = NewLogical(expr, operator, right)
 	}
 
 	return expr, true
@@ -374,12 +949,84 @@ func (p *Parser) equality() (Expr, bool) {
 }
 
 func (p *Parser) comparison() (Expr, bool) {
-	expr, ok := p.term()
+	expr, ok := p.bitOr()
 	if !ok {
 		return nil, false
 	}
 
 	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+		operator := *p.previous()
+		right, ok := p.bitOr()
+		if !ok {
+			return nil, false
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, true
+}
+
+func (p *Parser) bitOr() (Expr, bool) {
+	expr, ok := p.bitXor()
+	if !ok {
+		return nil, false
+	}
+
+	for p.match(PIPE) {
+		operator := *p.previous()
+		right, ok := p.bitXor()
+		if !ok {
+			return nil, false
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, true
+}
+
+func (p *Parser) bitXor() (Expr, bool) {
+	expr, ok := p.bitAnd()
+	if !ok {
+		return nil, false
+	}
+
+	for p.match(CARET) {
+		operator := *p.previous()
+		right, ok := p.bitAnd()
+		if !ok {
+			return nil, false
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, true
+}
+
+func (p *Parser) bitAnd() (Expr, bool) {
+	expr, ok := p.shift()
+	if !ok {
+		return nil, false
+	}
+
+	for p.match(AMPERSAND) {
+		operator := *p.previous()
+		right, ok := p.shift()
+		if !ok {
+			return nil, false
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, true
+}
+
+func (p *Parser) shift() (Expr, bool) {
+	expr, ok := p.term()
+	if !ok {
+		return nil, false
+	}
+
+	for p.match(LESS_LESS, GREATER_GREATER) {
 		operator := *p.previous()
 		right, ok := p.term()
 		if !ok {
@@ -415,7 +1062,7 @@ func (p *Parser) factor() (Expr, bool) {
 		return nil, false
 	}
 
-	for p.match(SLASH, STAR) {
+	for p.match(SLASH, STAR, TILDE_SLASH, PERCENT) {
 		operator := *p.previous()
 		right, ok := p.unary()
 		if !ok {
@@ -428,7 +1075,7 @@ func (p *Parser) factor() (Expr, bool) {
 }
 
 func (p *Parser) unary() (Expr, bool) {
-	if p.match(BANG, MINUS) {
+	if p.match(BANG, MINUS, TILDE) {
 		operator := *p.previous()
 		right, ok := p.unary()
 		if !ok {
@@ -437,32 +1084,122 @@ func (p *Parser) unary() (Expr, bool) {
 		return NewUnary(operator, right), true
 	}
 
-	return p.call()
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		target, ok := p.unary()
+		if !ok {
+			return nil, false
+		}
+		if !p.isAssignable(target) {
+			p.reportError(operator, "Invalid assignment target.")
+			return nil, false
+		}
+		return NewCompoundAssign(target, *operator, NewLiteral(int64(1)), false), true
+	}
+
+	return p.power()
 }
 
-func (p *Parser) call() (Expr, bool) {
-	expr, ok := p.primary()
+// power は累乗を構文解析する.右結合で,右辺には単項演算子を書ける(ex: 2 ** -1).
+// 左辺の単項演算子よりも強く結合する(ex: -2 ** 2 は -(2 ** 2)).
+func (p *Parser) power() (Expr, bool) {
+	expr, ok := p.postfix()
 	if !ok {
 		return nil, false
 	}
 
-	for p.match(LEFT_PAREN) {
-		expr, ok = p.finishCall(expr)
+	if p.match(STAR_STAR) {
+		operator := *p.previous()
+		right, ok := p.unary()
 		if !ok {
 			return nil, false
 		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, true
+}
+
+func (p *Parser) postfix() (Expr, bool) {
+	expr, ok := p.call()
+	if !ok {
+		return nil, false
+	}
+
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		if !p.isAssignable(expr) {
+			p.reportError(operator, "Invalid assignment target.")
+			return nil, false
+		}
+		return NewCompoundAssign(expr, *operator, NewLiteral(int64(1)), true), true
+	}
+
+	return expr, true
+}
+
+func (p *Parser) call() (Expr, bool) {
+	expr, ok := p.primary()
+	if !ok {
+		return nil, false
+	}
+
+	// ?.を含む呼び出しやプロパティ参照の連なりは,?.の左辺がnilの場合に残りをすべて飛ばしてnilになる.
+	optional := false
+	for {
+		if p.match(LEFT_PAREN) {
+			expr, ok = p.finishCall(expr, false)
+			if !ok {
+				return nil, false
+			}
+		} else if p.match(DOT) {
+			name, ok := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			if !ok {
+				return nil, false
+			}
+			expr = NewGet(expr, *name, false)
+		} else if p.match(QUESTION_DOT) {
+			optional = true
+			if p.match(LEFT_PAREN) {
+				expr, ok = p.finishCall(expr, true)
+				if !ok {
+					return nil, false
+				}
+			} else {
+				name, ok := p.consume(IDENTIFIER, "Expect property name or '(' after '?.'.")
+				if !ok {
+					return nil, false
+				}
+				expr = NewGet(expr, *name, true)
+			}
+		} else if p.match(LEFT_BRACKET) {
+			index, ok := p.expression()
+			if !ok {
+				return nil, false
+			}
+			bracket, ok := p.consume(RIGHT_BRACKET, "Expect ']' after index.")
+			if !ok {
+				return nil, false
+			}
+			expr = NewSubscript(expr, *bracket, index)
+		} else {
+			break
+		}
 	}
 
+	if optional {
+		return NewOptionalChain(expr), true
+	}
 	return expr, true
 }
 
-func (p *Parser) finishCall(callee Expr) (Expr, bool) {
+func (p *Parser) finishCall(callee Expr, optional bool) (Expr, bool) {
 	arguments := make([]Expr, 0)
 	if !p.check(RIGHT_PAREN) {
 		for con := true; con; con = p.match(COMMA) {
 			// c言語で実装するバイトコードインタープリタcloxでは引数に上限がある。互換性をもたせるため同じ制限を加えている。
 			if len(arguments) >= 255 {
-				parserResolverError(p.peek(), "Can't have more than 255 arguments.")
+				p.reportError(p.peek(), "Can't have more than 255 arguments.")
 			}
 			expr, ok := p.expression()
 			if !ok {
@@ -478,7 +1215,7 @@ func (p *Parser) finishCall(callee Expr) (Expr, bool) {
 		return nil, false
 	}
 
-	return NewCall(callee, *paren, arguments), true
+	return NewCall(callee, *paren, arguments, optional), true
 }
 
 func (p *Parser) primary() (Expr, bool) {
@@ -491,8 +1228,30 @@ func (p *Parser) primary() (Expr, bool) {
 		return NewLiteral(nil), true
 	case p.match(NUMBER, STRING):
 		return NewLiteral(p.previous().Literal), true
+	case p.match(INTERPOLATION):
+		return p.interpolation()
+	case p.match(SUPER):
+		keyword := *p.previous()
+		_, ok := p.consume(DOT, "Expect '.' after 'super'.")
+		if !ok {
+			return nil, false
+		}
+		method, ok := p.consume(IDENTIFIER, "Expect superclass method name.")
+		if !ok {
+			return nil, false
+		}
+		return NewSuper(keyword, *method), true
+	case p.match(FUN):
+		return p.lambda()
+	case p.match(THIS):
+		return NewThis(*p.previous()), true
 	case p.match(IDENTIFIER):
 		return NewVariable(*p.previous()), true
+	case p.match(LEFT_BRACKET):
+		return p.list()
+	case p.match(LEFT_BRACE):
+		// 文の先頭の'{'はstatementでブロックとして扱われるので,ここに来るのは式の位置にある場合だけ.
+		return p.mapLiteral()
 	case p.match(LEFT_PAREN):
 		expr, ok := p.expression()
 		if !ok {
@@ -505,10 +1264,90 @@ func (p *Parser) primary() (Expr, bool) {
 		return NewGrouping(expr), true
 	}
 
-	parserResolverError(p.peek(), "Expect expression.")
+	p.reportError(p.peek(), "Expect expression.")
 	return nil, false
 }
 
+// interpolation は文字列補間を構文解析する.
+// "a${x}b${y}c" はINTERPOLATION("a"),x,INTERPOLATION("b"),y,STRING("c")のトークン列になっている.
+func (p *Parser) interpolation() (Expr, bool) {
+	parts := make([]Expr, 0)
+	for {
+		if p.previous().Literal != "" {
+			parts = append(parts, NewLiteral(p.previous().Literal))
+		}
+		expr, ok := p.expression()
+		if !ok {
+			return nil, false
+		}
+		parts = append(parts, expr)
+
+		if !p.match(INTERPOLATION) {
+			break
+		}
+	}
+
+	end, ok := p.consume(STRING, "Expect end of string interpolation.")
+	if !ok {
+		return nil, false
+	}
+	if end.Literal != "" {
+		parts = append(parts, NewLiteral(end.Literal))
+	}
+
+	return NewInterpolation(parts), true
+}
+
+func (p *Parser) list() (Expr, bool) {
+	elements := make([]Expr, 0)
+	if !p.check(RIGHT_BRACKET) {
+		for con := true; con; con = p.match(COMMA) {
+			element, ok := p.expression()
+			if !ok {
+				return nil, false
+			}
+			elements = append(elements, element)
+		}
+	}
+
+	_, ok := p.consume(RIGHT_BRACKET, "Expect ']' after list elements.")
+	if !ok {
+		return nil, false
+	}
+
+	return NewList(elements), true
+}
+
+func (p *Parser) mapLiteral() (Expr, bool) {
+	keys := make([]Expr, 0)
+	values := make([]Expr, 0)
+	if !p.check(RIGHT_BRACE) {
+		for con := true; con; con = p.match(COMMA) {
+			key, ok := p.expression()
+			if !ok {
+				return nil, false
+			}
+			_, ok = p.consume(COLON, "Expect ':' after map key.")
+			if !ok {
+				return nil, false
+			}
+			value, ok := p.expression()
+			if !ok {
+				return nil, false
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+	}
+
+	_, ok := p.consume(RIGHT_BRACE, "Expect '}' after map entries.")
+	if !ok {
+		return nil, false
+	}
+
+	return NewMap(keys, values), true
+}
+
 func (p *Parser) match(types ...TokenType) bool {
 	for _, typ := range types {
 		if p.check(typ) {
@@ -519,6 +1358,16 @@ func (p *Parser) match(types ...TokenType) bool {
 	return false
 }
 
+// matchWord は現在のトークンがwordという名前の識別子なら読み進める.
+// asやfromはimport文の中でだけ意味を持つので,予約語にせずに名前で調べる.
+func (p *Parser) matchWord(word string) bool {
+	if p.check(IDENTIFIER) && p.peek().Lexeme == word {
+		p.advance()
+		return true
+	}
+	return false
+}
+
 func (p *Parser) check(typ TokenType) bool {
 	if p.isAtEnd() {
 		return false
@@ -526,6 +1375,20 @@ func (p *Parser) check(typ TokenType) bool {
 	return p.peek().Typ == typ
 }
 
+func (p *Parser) checkNext(typ TokenType) bool {
+	return p.checkAhead(1, typ)
+}
+
+// checkAhead は現在からdistance個先のトークンの種類を調べる.
+func (p *Parser) checkAhead(distance int, typ TokenType) bool {
+	for n := 0; n < distance; n++ {
+		if p.tokens[p.current+n].Typ == EOF {
+			return false
+		}
+	}
+	return p.tokens[p.current+distance].Typ == typ
+}
+
 func (p *Parser) advance() *Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -547,7 +1410,7 @@ func (p *Parser) previous() *Token {
 
 func (p *Parser) consume(typ TokenType, message string) (*Token, bool) {
 	if !p.check(typ) {
-		parserResolverError(p.peek(), message)
+		p.reportError(p.peek(), message)
 		return nil, false
 	}
 
@@ -564,22 +1427,38 @@ func (p *Parser) synchronize() {
 		switch p.peek().Typ {
 		case CLASS:
 			return
+		case CONST:
+			return
 		case FOR:
 			return
 		case FUN:
 			return
 		case IF:
 			return
+		case IMPORT:
+			return
+		case MATCH:
+			return
 		case PRINT:
 			return
 		case RETURN:
 			return
+		case THROW:
+			return
+		case TRY:
+			return
 		case VAR:
 			return
 		case WHILE:
 			return
+		case YIELD:
+			return
 		}
 
 		p.advance()
 	}
 }
+
+func (p *Parser) reportError(token *Token, message string) {
+	p.diagnostics.Report(newTokenDiagnostic(PARSE_PHASE, token, message))
+}
@@ -10,30 +10,110 @@ import (
 type AstPrinter struct{}
 
 func (a *AstPrinter) Print(expr mygolox.Expr) (str string) {
-	if str, ok := expr.Accept(a).(string); ok {
+	value, _ := expr.Accept(a)
+	if str, ok := value.(string); ok {
 		return str
 	}
 	log.Println("failed type assertion")
 	return
 }
 
-func (a *AstPrinter) VisitBinaryExpr(binary mygolox.Binary) any {
-	return a.parenthesize(binary.Operator.Lexeme, binary.Left, binary.Right)
+func (a *AstPrinter) VisitAssignExpr(assign *mygolox.Assign) (any, error) {
+	return a.parenthesize("= "+assign.Name.Lexeme, assign.Value), nil
 }
 
-func (a *AstPrinter) VisitGroupingExpr(grouping mygolox.Grouping) any {
-	return a.parenthesize("group", grouping.Expression)
+func (a *AstPrinter) VisitBinaryExpr(binary *mygolox.Binary) (any, error) {
+	return a.parenthesize(binary.Operator.Lexeme, binary.Left, binary.Right), nil
 }
 
-func (a *AstPrinter) VisitLiteralExpr(literal mygolox.Literal) any {
+func (a *AstPrinter) VisitCallExpr(call *mygolox.Call) (any, error) {
+	return a.parenthesize("call", append([]mygolox.Expr{call.Callee}, call.Arguments...)...), nil
+}
+
+func (a *AstPrinter) VisitCompoundAssignExpr(compoundAssign *mygolox.CompoundAssign) (any, error) {
+	if compoundAssign.Postfix {
+		return a.parenthesize("postfix "+compoundAssign.Operator.Lexeme, compoundAssign.Target), nil
+	}
+	return a.parenthesize(compoundAssign.Operator.Lexeme, compoundAssign.Target, compoundAssign.Value), nil
+}
+
+func (a *AstPrinter) VisitConditionalExpr(conditional *mygolox.Conditional) (any, error) {
+	return a.parenthesize("?:", conditional.Condition, conditional.ThenBranch, conditional.ElseBranch), nil
+}
+
+func (a *AstPrinter) VisitGetExpr(get *mygolox.Get) (any, error) {
+	return a.parenthesize(". "+get.Name.Lexeme, get.Object), nil
+}
+
+func (a *AstPrinter) VisitGroupingExpr(grouping *mygolox.Grouping) (any, error) {
+	return a.parenthesize("group", grouping.Expression), nil
+}
+
+func (a *AstPrinter) VisitSubscriptExpr(subscript *mygolox.Subscript) (any, error) {
+	return a.parenthesize("[]", subscript.Object, subscript.Index), nil
+}
+
+func (a *AstPrinter) VisitInterpolationExpr(interpolation *mygolox.Interpolation) (any, error) {
+	return a.parenthesize("interpolation", interpolation.Parts...), nil
+}
+
+func (a *AstPrinter) VisitLambdaExpr(lambda *mygolox.Lambda) (any, error) {
+	params := make([]string, 0, len(lambda.Declaration.Params))
+	for _, param := range lambda.Declaration.Params {
+		params = append(params, param.Lexeme)
+	}
+	return "(fun (" + strings.Join(params, " ") + "))", nil
+}
+
+func (a *AstPrinter) VisitListExpr(list *mygolox.List) (any, error) {
+	return a.parenthesize("list", list.Elements...), nil
+}
+
+func (a *AstPrinter) VisitLiteralExpr(literal *mygolox.Literal) (any, error) {
 	if literal.Value == nil {
-		return nil
+		return "nil", nil
 	}
-	return fmt.Sprint(literal.Value)
+	return fmt.Sprint(literal.Value), nil
+}
+
+func (a *AstPrinter) VisitLogicalExpr(logical *mygolox.Logical) (any, error) {
+	return a.parenthesize(logical.Operator.Lexeme, logical.Left, logical.Right), nil
+}
+
+func (a *AstPrinter) VisitMapExpr(m *mygolox.Map) (any, error) {
+	exprs := make([]mygolox.Expr, 0, len(m.Keys)*2)
+	for i, key := range m.Keys {
+		exprs = append(exprs, key, m.Values[i])
+	}
+	return a.parenthesize("map", exprs...), nil
+}
+
+func (a *AstPrinter) VisitOptionalChainExpr(optionalChain *mygolox.OptionalChain) (any, error) {
+	return a.parenthesize("?.", optionalChain.Expression), nil
+}
+
+func (a *AstPrinter) VisitSetExpr(set *mygolox.Set) (any, error) {
+	return a.parenthesize("= "+set.Name.Lexeme, set.Object, set.Value), nil
+}
+
+func (a *AstPrinter) VisitSetSubscriptExpr(setSubscript *mygolox.SetSubscript) (any, error) {
+	return a.parenthesize("[]=", setSubscript.Object, setSubscript.Index, setSubscript.Value), nil
+}
+
+func (a *AstPrinter) VisitSuperExpr(super *mygolox.Super) (any, error) {
+	return "super." + super.Method.Lexeme, nil
+}
+
+func (a *AstPrinter) VisitThisExpr(this *mygolox.This) (any, error) {
+	return "this", nil
+}
+
+func (a *AstPrinter) VisitUnaryExpr(unary *mygolox.Unary) (any, error) {
+	return a.parenthesize(unary.Operator.Lexeme, unary.Right), nil
 }
 
-func (a *AstPrinter) VisitUnaryExpr(unary mygolox.Unary) any {
-	return a.parenthesize(unary.Operator.Lexeme, unary.Right)
+func (a *AstPrinter) VisitVariableExpr(variable *mygolox.Variable) (any, error) {
+	return variable.Name.Lexeme, nil
 }
 
 func (a *AstPrinter) parenthesize(name string, exprs ...mygolox.Expr) string {
@@ -43,7 +123,8 @@ func (a *AstPrinter) parenthesize(name string, exprs ...mygolox.Expr) string {
 	builder.WriteString(name)
 	for _, expr := range exprs {
 		builder.WriteString(" ")
-		if str, ok := expr.Accept(a).(string); ok {
+		value, _ := expr.Accept(a)
+		if str, ok := value.(string); ok {
 			builder.WriteString(str)
 		}
 	}
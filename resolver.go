@@ -5,13 +5,26 @@ type Resolver struct {
 	Interpreter     *Interpreter
 	Scopes          *stack
 	currentFunction FunctionType
-}
-
-func NewResolver(interpreter *Interpreter) *Resolver {
+	currentClass    ClassType
+	currentLoop     LoopType
+	// inGenerator は解決中の関数がジェネレータかどうか.
+	inGenerator bool
+	// globalConstants はトップレベルでconstで宣言された名前.
+	globalConstants map[string]bool
+	// diagnostics は見つけたエラーの報告先.
+	diagnostics DiagnosticSink
+}
+
+// NewResolver はResolverのコンストラクタ.diagnosticsがnilの場合,エラーは捨てられる.
+func NewResolver(interpreter *Interpreter, diagnostics DiagnosticSink) *Resolver {
 	return &Resolver{
 		Interpreter:     interpreter,
+		globalConstants: map[string]bool{},
+		diagnostics:     orDiscard(diagnostics),
 		Scopes:          newStack(),
 		currentFunction: NONE,
+		currentClass:    NO_CLASS,
+		currentLoop:     NO_LOOP,
 	}
 }
 
@@ -20,15 +33,43 @@ type FunctionType int
 const (
 	NONE FunctionType = iota
 	FUNCTION
+	INITIALIZER
+	METHOD
+)
+
+// ClassType は解決中のコードがクラスの内側にあるかどうかを表す.
+// TokenTypeのCLASSと名前が衝突しないようにしている.
+type ClassType int
+
+const (
+	NO_CLASS ClassType = iota
+	IN_CLASS
+	IN_SUBCLASS
+)
+
+// LoopType は解決中のコードがループの内側にあるかどうかを表す.
+type LoopType int
+
+const (
+	NO_LOOP LoopType = iota
+	IN_LOOP
 )
 
+// scopeVariable はスコープ内で宣言された変数の状態.
+type scopeVariable struct {
+	// defined は初期化子の解決が終わり,変数を参照できるようになったかどうか.
+	defined bool
+	// constant はconstで宣言された,再代入できない変数かどうか.
+	constant bool
+}
+
 type stack struct {
-	dataGroup []map[string]bool
+	dataGroup []map[string]scopeVariable
 }
 
 func newStack() *stack {
 	return &stack{
-		dataGroup: []map[string]bool{},
+		dataGroup: []map[string]scopeVariable{},
 	}
 }
 
@@ -36,11 +77,11 @@ func (s *stack) size() int {
 	return len(s.dataGroup)
 }
 
-func (s *stack) push(data map[string]bool) {
+func (s *stack) push(data map[string]scopeVariable) {
 	s.dataGroup = append(s.dataGroup, data)
 }
 
-func (s *stack) pop() map[string]bool {
+func (s *stack) pop() map[string]scopeVariable {
 	value := s.dataGroup[s.size()-1]
 	s.dataGroup = s.dataGroup[:s.size()-1]
 	return value
@@ -50,14 +91,14 @@ func (s *stack) isEmpty() bool {
 	return s.size() == 0
 }
 
-func (s *stack) peek() *map[string]bool {
+func (s *stack) peek() *map[string]scopeVariable {
 	if s.isEmpty() {
 		return nil
 	}
 	return &s.dataGroup[s.size()-1]
 }
 
-func (s *stack) get(i int) *map[string]bool {
+func (s *stack) get(i int) *map[string]scopeVariable {
 	if i < 0 || s.size() <= i {
 		return nil
 	}
@@ -71,117 +112,378 @@ func (r *Resolver) ResolveStmts(statements []Stmt) {
 	}
 }
 
-func (r *Resolver) VisitBlockStmt(stmt Block) any {
+func (r *Resolver) VisitBlockStmt(stmt *Block) Completion {
 	r.beginScope()
 	r.ResolveStmts(stmt.Statements)
 	r.endScope()
-	return nil
+	return Completion{}
+}
+
+func (r *Resolver) VisitBreakStmt(stmt *Break) Completion {
+	if r.currentLoop == NO_LOOP {
+		r.reportError(&stmt.Keyword, "Can't use 'break' outside of a loop.")
+	}
+	return Completion{}
+}
+
+func (r *Resolver) VisitClassStmt(stmt *Class) Completion {
+	enclosingClass := r.currentClass
+	r.currentClass = IN_CLASS
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+
+	if stmt.Superclass != nil {
+		if stmt.Name.Lexeme == stmt.Superclass.Name.Lexeme {
+			r.reportError(&stmt.Superclass.Name, "A class can't inherit from itself.")
+		}
+
+		r.currentClass = IN_SUBCLASS
+		r.resolveExpr(stmt.Superclass)
+
+		r.beginScope()
+		(*r.Scopes.peek())["super"] = scopeVariable{defined: true}
+	}
+
+	r.beginScope()
+	(*r.Scopes.peek())["this"] = scopeVariable{defined: true}
+
+	for _, method := range stmt.Methods {
+		declaration := METHOD
+		if method.Name.Lexeme == "init" {
+			declaration = INITIALIZER
+		}
+		r.resolveFunction(method, declaration)
+	}
+
+	r.endScope()
+
+	if stmt.Superclass != nil {
+		r.endScope()
+	}
+
+	r.currentClass = enclosingClass
+	return Completion{}
 }
 
-func (r *Resolver) VisitExpressStmt(stmt Express) any {
+func (r *Resolver) VisitContinueStmt(stmt *Continue) Completion {
+	if r.currentLoop == NO_LOOP {
+		r.reportError(&stmt.Keyword, "Can't use 'continue' outside of a loop.")
+	}
+	return Completion{}
+}
+
+func (r *Resolver) VisitExpressStmt(stmt *Express) Completion {
 	r.resolveExpr(stmt.Expression)
-	return nil
+	return Completion{}
 }
 
-func (r *Resolver) VisitIfStmt(stmt If) any {
+func (r *Resolver) VisitIfStmt(stmt *If) Completion {
 	r.resolveExpr(stmt.Condition)
 	r.resolveStmt(stmt.ThenBranch)
 	if stmt.ElseBranch != nil {
 		r.resolveStmt(stmt.ElseBranch)
 	}
-	return nil
+	return Completion{}
 }
 
-func (r *Resolver) VisitPrintStmt(stmt Print) any {
+func (r *Resolver) VisitImportStmt(stmt *Import) Completion {
+	if !r.Scopes.isEmpty() {
+		r.reportError(&stmt.Keyword, "Can only import at top level.")
+	}
+
+	if stmt.Alias != nil {
+		r.declare(*stmt.Alias)
+		r.define(*stmt.Alias)
+	}
+	for _, name := range stmt.Names {
+		r.declare(name)
+		r.define(name)
+	}
+	return Completion{}
+}
+
+func (r *Resolver) VisitMatchStmt(stmt *Match) Completion {
+	r.resolveExpr(stmt.Subject)
+	for _, arm := range stmt.Arms {
+		// 束縛パターンの変数はarmごとの新しいスコープに入る.
+		r.beginScope()
+		for _, name := range arm.bindings() {
+			r.declare(name)
+			r.define(name)
+		}
+		if arm.Guard != nil {
+			r.resolveExpr(arm.Guard)
+		}
+		r.resolveStmt(arm.Body)
+		r.endScope()
+	}
+	return Completion{}
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *Print) Completion {
 	r.resolveExpr(stmt.Expression)
-	return nil
+	return Completion{}
 }
 
-func (r *Resolver) VisitReturnStmt(stmt Return) any {
+func (r *Resolver) VisitReturnStmt(stmt *Return) Completion {
 	if r.currentFunction == NONE {
-		parserResolverError(&stmt.Keyword, "Can't return from top-level code.")
+		r.reportError(&stmt.Keyword, "Can't return from top-level code.")
 	}
 	if stmt.Value != nil {
+		if r.currentFunction == INITIALIZER {
+			r.reportError(&stmt.Keyword, "Can't return a value from an initializer.")
+		}
+		if r.inGenerator {
+			r.reportError(&stmt.Keyword, "Can't return a value from a generator.")
+		}
 		r.resolveExpr(stmt.Value)
 	}
 
-	return nil
+	return Completion{}
 }
 
-func (r *Resolver) VisitWhileStmt(stmt While) any {
+func (r *Resolver) VisitThrowStmt(stmt *Throw) Completion {
+	r.resolveExpr(stmt.Value)
+	return Completion{}
+}
+
+func (r *Resolver) VisitTryStmt(stmt *Try) Completion {
+	r.beginScope()
+	r.ResolveStmts(stmt.Body)
+	r.endScope()
+
+	if stmt.CatchParam != nil {
+		r.beginScope()
+		r.declare(*stmt.CatchParam)
+		r.define(*stmt.CatchParam)
+		r.ResolveStmts(stmt.CatchBody)
+		r.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		r.beginScope()
+		r.ResolveStmts(stmt.FinallyBody)
+		r.endScope()
+	}
+
+	return Completion{}
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *While) Completion {
+	enclosingLoop := r.currentLoop
+	r.currentLoop = IN_LOOP
+
 	r.resolveExpr(stmt.condition)
 	r.resolveStmt(stmt.body)
-	return nil
+	if stmt.increment != nil {
+		r.resolveExpr(stmt.increment)
+	}
+
+	r.currentLoop = enclosingLoop
+	return Completion{}
+}
+
+func (r *Resolver) VisitYieldStmt(stmt *Yield) Completion {
+	if r.currentFunction == NONE {
+		r.reportError(&stmt.Keyword, "Can't yield from top-level code.")
+	}
+	if r.currentFunction == INITIALIZER {
+		r.reportError(&stmt.Keyword, "Can't yield from an initializer.")
+	}
+	r.resolveExpr(stmt.Value)
+	return Completion{}
+}
+
+func (r *Resolver) VisitForInStmt(stmt *ForIn) Completion {
+	r.resolveExpr(stmt.Iterable)
+
+	enclosingLoop := r.currentLoop
+	r.currentLoop = IN_LOOP
+
+	r.beginScope()
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	r.resolveStmt(stmt.Body)
+	r.endScope()
+
+	r.currentLoop = enclosingLoop
+	return Completion{}
 }
 
-func (r *Resolver) VisitFunctionStmt(stmt Function) any {
+func (r *Resolver) VisitFunctionStmt(stmt *Function) Completion {
 	r.declare(stmt.Name)
 	r.define(stmt.Name)
 	r.resolveFunction(stmt, FUNCTION)
-	return nil
+	return Completion{}
 }
 
-func (r *Resolver) VisitVarStmt(stmt Var) any {
+func (r *Resolver) VisitVarStmt(stmt *Var) Completion {
 	r.declare(stmt.Name)
 	if stmt.Initializer != nil {
 		r.resolveExpr(stmt.Initializer)
 	}
-	r.define(stmt.Name)
-	return nil
+	if stmt.Constant {
+		r.defineConstant(stmt.Name)
+	} else {
+		r.define(stmt.Name)
+	}
+	return Completion{}
 }
 
-func (r *Resolver) VisitAssignExpr(expr Assign) any {
+func (r *Resolver) VisitAssignExpr(expr *Assign) (any, error) {
 	r.resolveExpr(expr.Value)
+	r.checkAssignable(expr.Name)
 	r.resolveLocal(expr, expr.Name)
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitBinaryExpr(expr Binary) any {
+func (r *Resolver) VisitBinaryExpr(expr *Binary) (any, error) {
 	r.resolveExpr(expr.Left)
 	r.resolveExpr(expr.Right)
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitCallExpr(expr Call) any {
+func (r *Resolver) VisitCallExpr(expr *Call) (any, error) {
 	r.resolveExpr(expr.Callee)
 
 	for _, argument := range expr.Arguments {
 		r.resolveExpr(argument)
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitGroupingExpr(expr Grouping) any {
+func (r *Resolver) VisitCompoundAssignExpr(expr *CompoundAssign) (any, error) {
+	r.resolveExpr(expr.Value)
+	// 代入先の変数はCompoundAssign自身をキーとして一度だけ解決する.
+	switch target := expr.Target.(type) {
+	case *Variable:
+		r.checkAssignable(target.Name)
+		r.resolveLocal(expr, target.Name)
+	case *Get:
+		r.resolveExpr(target.Object)
+	case *Subscript:
+		r.resolveExpr(target.Object)
+		r.resolveExpr(target.Index)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitConditionalExpr(expr *Conditional) (any, error) {
+	r.resolveExpr(expr.Condition)
+	r.resolveExpr(expr.ThenBranch)
+	r.resolveExpr(expr.ElseBranch)
+	return nil, nil
+}
+
+func (r *Resolver) VisitGetExpr(expr *Get) (any, error) {
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitGroupingExpr(expr *Grouping) (any, error) {
 	r.resolveExpr(expr.Expression)
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitLiteralExpr(expr Literal) any {
-	return nil
+func (r *Resolver) VisitSubscriptExpr(expr *Subscript) (any, error) {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil, nil
 }
 
-func (r *Resolver) VisitLogicalExpr(expr Logical) any {
+func (r *Resolver) VisitInterpolationExpr(expr *Interpolation) (any, error) {
+	for _, part := range expr.Parts {
+		r.resolveExpr(part)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitLambdaExpr(expr *Lambda) (any, error) {
+	r.resolveFunction(expr.Declaration, FUNCTION)
+	return nil, nil
+}
+
+func (r *Resolver) VisitListExpr(expr *List) (any, error) {
+	for _, element := range expr.Elements {
+		r.resolveExpr(element)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitMapExpr(expr *Map) (any, error) {
+	for n, key := range expr.Keys {
+		r.resolveExpr(key)
+		r.resolveExpr(expr.Values[n])
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitLiteralExpr(expr *Literal) (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitLogicalExpr(expr *Logical) (any, error) {
 	r.resolveExpr(expr.Left)
 	r.resolveExpr(expr.Right)
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitUnaryExpr(expr Unary) any {
+func (r *Resolver) VisitOptionalChainExpr(expr *OptionalChain) (any, error) {
+	r.resolveExpr(expr.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSetExpr(expr *Set) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSetSubscriptExpr(expr *SetSubscript) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSuperExpr(expr *Super) (any, error) {
+	if r.currentClass == NO_CLASS {
+		r.reportError(&expr.Keyword, "Can't use 'super' outside of a class.")
+	} else if r.currentClass != IN_SUBCLASS {
+		r.reportError(&expr.Keyword, "Can't use 'super' in a class with no superclass.")
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitThisExpr(expr *This) (any, error) {
+	if r.currentClass == NO_CLASS {
+		r.reportError(&expr.Keyword, "Can't use 'this' outside of a class.")
+		return nil, nil
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitUnaryExpr(expr *Unary) (any, error) {
 	r.resolveExpr(expr.Right)
-	return nil
+	return nil, nil
 }
 
-func (r *Resolver) VisitVariableExpr(expr Variable) any {
+func (r *Resolver) VisitVariableExpr(expr *Variable) (any, error) {
 	// 変数がそれ自身の初期化子の中でアクセスされてるかのチェック(ex: var a = a;).
 	if !r.Scopes.isEmpty() {
-		if v, ok := (*r.Scopes.peek())[expr.Name.Lexeme]; ok && !v {
-			parserResolverError(&expr.Name, "Can't read local variable in its own initializer.")
+		if v, ok := (*r.Scopes.peek())[expr.Name.Lexeme]; ok && !v.defined {
+			r.reportError(&expr.Name, "Can't read local variable in its own initializer.")
 		}
 	}
 
 	r.resolveLocal(expr, expr.Name)
-	return nil
+	return nil, nil
 }
 
 func (r *Resolver) resolveStmt(stmt Stmt) {
@@ -192,21 +494,36 @@ func (r *Resolver) resolveExpr(expr Expr) {
 	expr.Accept(r)
 }
 
-func (r *Resolver) resolveFunction(function Function, typ FunctionType) {
+func (r *Resolver) resolveFunction(function *Function, typ FunctionType) {
 	enclosingFunction := r.currentFunction
 	r.currentFunction = typ
+	// 関数の境界を越えてbreakやcontinueすることはできない.
+	enclosingLoop := r.currentLoop
+	r.currentLoop = NO_LOOP
+	enclosingGenerator := r.inGenerator
+	r.inGenerator = function.Generator
 	r.beginScope()
-	for _, param := range function.Params {
+	// デフォルト値の式は,それより前の仮引数を参照できる.
+	for i, param := range function.Params {
+		if function.Defaults[i] != nil {
+			r.resolveExpr(function.Defaults[i])
+		}
 		r.declare(param)
 		r.define(param)
 	}
+	if function.Rest != nil {
+		r.declare(*function.Rest)
+		r.define(*function.Rest)
+	}
 	r.ResolveStmts(function.Body)
 	r.endScope()
+	r.inGenerator = enclosingGenerator
+	r.currentLoop = enclosingLoop
 	r.currentFunction = enclosingFunction
 }
 
 func (r *Resolver) beginScope() {
-	r.Scopes.push(map[string]bool{})
+	r.Scopes.push(map[string]scopeVariable{})
 }
 
 func (r *Resolver) endScope() {
@@ -215,20 +532,51 @@ func (r *Resolver) endScope() {
 
 func (r *Resolver) declare(name Token) {
 	if r.Scopes.isEmpty() {
+		if r.globalConstants[name.Lexeme] {
+			r.reportError(&name, "Can't redeclare constant '"+name.Lexeme+"'.")
+		}
 		return
 	}
 	scope := *r.Scopes.peek()
 	if _, ok := scope[name.Lexeme]; ok {
-		parserResolverError(&name, "Already a variable with this name in this scope.")
+		r.reportError(&name, "Already a variable with this name in this scope.")
 	}
-	scope[name.Lexeme] = false
+	scope[name.Lexeme] = scopeVariable{}
 }
 
 func (r *Resolver) define(name Token) {
 	if r.Scopes.isEmpty() {
 		return
 	}
-	(*r.Scopes.peek())[name.Lexeme] = true
+	scope := *r.Scopes.peek()
+	variable := scope[name.Lexeme]
+	variable.defined = true
+	scope[name.Lexeme] = variable
+}
+
+// defineConstant はnameを再代入できない変数として定義する.
+func (r *Resolver) defineConstant(name Token) {
+	if r.Scopes.isEmpty() {
+		r.globalConstants[name.Lexeme] = true
+		return
+	}
+	(*r.Scopes.peek())[name.Lexeme] = scopeVariable{defined: true, constant: true}
+}
+
+// checkAssignable はnameがconstで宣言された変数であればエラーを報告する.
+// トップレベルの定数は,代入より前に宣言されたものだけをここで検査し,残りは実行時にEnvironmentが検査する.
+func (r *Resolver) checkAssignable(name Token) {
+	for i := r.Scopes.size() - 1; i >= 0; i-- {
+		if v, ok := (*r.Scopes.get(i))[name.Lexeme]; ok {
+			if v.constant {
+				r.reportError(&name, "Can't assign to constant '"+name.Lexeme+"'.")
+			}
+			return
+		}
+	}
+	if r.globalConstants[name.Lexeme] {
+		r.reportError(&name, "Can't assign to constant '"+name.Lexeme+"'.")
+	}
 }
 
 func (r *Resolver) resolveLocal(expr Expr, name Token) {
@@ -239,3 +587,7 @@ func (r *Resolver) resolveLocal(expr Expr, name Token) {
 		}
 	}
 }
+
+func (r *Resolver) reportError(token *Token, message string) {
+	r.diagnostics.Report(newTokenDiagnostic(RESOLVE_PHASE, token, message))
+}
@@ -1,11 +0,0 @@
-package mygolox
-
-type ReturnValue struct {
-	value any
-}
-
-func NewReturnValue(value any) *ReturnValue {
-	return &ReturnValue{
-		value: value,
-	}
-}
@@ -1,6 +1,11 @@
 package mygolox
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 // Scanner は字句のスキャンを行うための構造体.java実装のloxにおけるScannerクラス.
 type Scanner struct {
@@ -10,34 +15,63 @@ type Scanner struct {
 	current  int
 	line     int
 	keywords map[string]TokenType
+	// interpolations は補間式をスキャン中の,入れ子になった文字列リテラルのスタック.
+	interpolations []stringLiteral
+	// docs は次のトークンに付けるドキュメントコメントの行.
+	docs []string
+	// diagnostics は見つけたエラーの報告先.
+	diagnostics DiagnosticSink
+}
+
+// stringLiteral はスキャン中の文字列リテラルの種類と,補間式の中で開いている'{'の数.
+type stringLiteral struct {
+	// triple は"""で囲まれた複数行の文字列かどうか.
+	triple bool
+	// indent は複数行の文字列の各行から取り除くインデントの幅.
+	indent int
+	braces int
 }
 
 // NewScanner はScannerのコンストラクタ.
-func NewScanner(source string) *Scanner {
+// diagnosticsがnilの場合,エラーは捨てられる.
+func NewScanner(source string, diagnostics DiagnosticSink) *Scanner {
 	keywords := map[string]TokenType{
-		"and":    AND,
-		"class":  CLASS,
-		"else":   ELSE,
-		"false":  FALSE,
-		"for":    FOR,
-		"fun":    FUN,
-		"if":     IF,
-		"nil":    NIL,
-		"or":     OR,
-		"print":  PRINT,
-		"return": RETURN,
-		"super":  SUPER,
-		"this":   THIS,
-		"true":   TRUE,
-		"var":    VAR,
-		"while":  WHILE,
+		"and":      AND,
+		"break":    BREAK,
+		"case":     CASE,
+		"catch":    CATCH,
+		"class":    CLASS,
+		"const":    CONST,
+		"continue": CONTINUE,
+		"else":     ELSE,
+		"false":    FALSE,
+		"finally":  FINALLY,
+		"for":      FOR,
+		"fun":      FUN,
+		"if":       IF,
+		"import":   IMPORT,
+		"in":       IN,
+		"match":    MATCH,
+		"nil":      NIL,
+		"or":       OR,
+		"print":    PRINT,
+		"return":   RETURN,
+		"super":    SUPER,
+		"this":     THIS,
+		"throw":    THROW,
+		"true":     TRUE,
+		"try":      TRY,
+		"var":      VAR,
+		"while":    WHILE,
+		"yield":    YIELD,
 	}
 	return &Scanner{
-		source:   source,
-		start:    0,
-		current:  0,
-		line:     1,
-		keywords: keywords,
+		source:      source,
+		start:       0,
+		current:     0,
+		line:        1,
+		keywords:    keywords,
+		diagnostics: orDiscard(diagnostics),
 	}
 }
 
@@ -48,7 +82,9 @@ func (s *Scanner) ScanTokens() []Token {
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, *NewToken(EOF, "", nil, s.line))
+	eof := NewToken(EOF, "", nil, s.line)
+	eof.Column = s.column(s.current)
+	s.tokens = append(s.tokens, *eof)
 	return s.tokens
 }
 
@@ -60,36 +96,106 @@ func (s *Scanner) scanToken() {
 	case ')':
 		s.addToken(RIGHT_PAREN, nil)
 	case '{':
+		if len(s.interpolations) > 0 {
+			s.interpolations[len(s.interpolations)-1].braces++
+		}
 		s.addToken(LEFT_BRACE, nil)
 	case '}':
+		if len(s.interpolations) > 0 {
+			top := len(s.interpolations) - 1
+			// 補間式を閉じる'}'なら文字列の続きをスキャンする.
+			if s.interpolations[top].braces == 0 {
+				literal := s.interpolations[top]
+				s.interpolations = s.interpolations[:top]
+				s.string(literal)
+				return
+			}
+			s.interpolations[top].braces--
+		}
 		s.addToken(RIGHT_BRACE, nil)
+	case '[':
+		s.addToken(LEFT_BRACKET, nil)
+	case ']':
+		s.addToken(RIGHT_BRACKET, nil)
 	case ',':
 		s.addToken(COMMA, nil)
+	case ':':
+		s.addToken(COLON, nil)
 	case '.':
-		s.addToken(DOT, nil)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.advance()
+			s.advance()
+			s.addToken(DOT_DOT_DOT, nil)
+		} else if s.match('.') {
+			s.addToken(DOT_DOT, nil)
+		} else {
+			s.addToken(DOT, nil)
+		}
 	case '-':
-		s.addToken(MINUS, nil)
+		if s.match('-') {
+			s.addToken(MINUS_MINUS, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: MINUS_EQUAL, false: MINUS}[s.match('=')], nil)
+		}
 	case '+':
-		s.addToken(PLUS, nil)
+		if s.match('+') {
+			s.addToken(PLUS_PLUS, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: PLUS_EQUAL, false: PLUS}[s.match('=')], nil)
+		}
 	case ';':
 		s.addToken(SEMICOLON, nil)
 	case '*':
-		s.addToken(STAR, nil)
+		if s.match('*') {
+			s.addToken(STAR_STAR, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: STAR_EQUAL, false: STAR}[s.match('=')], nil)
+		}
+	case '%':
+		s.addToken(PERCENT, nil)
+	case '&':
+		s.addToken(AMPERSAND, nil)
+	case '|':
+		s.addToken(PIPE, nil)
+	case '^':
+		s.addToken(CARET, nil)
+	case '?':
+		if s.match('?') {
+			s.addToken(QUESTION_QUESTION, nil)
+		} else if s.match('.') {
+			s.addToken(QUESTION_DOT, nil)
+		} else {
+			s.addToken(QUESTION, nil)
+		}
+	case '~':
+		s.addToken(map[bool]TokenType{true: TILDE_SLASH, false: TILDE}[s.match('/')], nil)
 	case '!':
 		s.addToken(map[bool]TokenType{true: BANG_EQUAL, false: BANG}[s.match('=')], nil)
 	case '=':
-		s.addToken(map[bool]TokenType{true: EQUAL_EQUAL, false: EQUAL}[s.match('=')], nil)
+		if s.match('>') {
+			s.addToken(ARROW, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: EQUAL_EQUAL, false: EQUAL}[s.match('=')], nil)
+		}
 	case '<':
-		s.addToken(map[bool]TokenType{true: LESS_EQUAL, false: LESS}[s.match('=')], nil)
+		if s.match('<') {
+			s.addToken(LESS_LESS, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: LESS_EQUAL, false: LESS}[s.match('=')], nil)
+		}
 	case '>':
-		s.addToken(map[bool]TokenType{true: GREATER_EQUAL, false: GREATER}[s.match('=')], nil)
+		if s.match('>') {
+			s.addToken(GREATER_GREATER, nil)
+		} else {
+			s.addToken(map[bool]TokenType{true: GREATER_EQUAL, false: GREATER}[s.match('=')], nil)
+		}
 	case '/':
 		if s.match('/') {
-			for s.peek() != '\n' && !s.isAtEnd() {
-				s.advance()
-			}
+			s.lineComment()
+		} else if s.match('*') {
+			s.blockComment()
 		} else {
-			s.addToken(SLASH, nil)
+			s.addToken(map[bool]TokenType{true: SLASH_EQUAL, false: SLASH}[s.match('=')], nil)
 		}
 	case ' ':
 	case '\r':
@@ -97,14 +203,22 @@ func (s *Scanner) scanToken() {
 	case '\n':
 		s.line++
 	case '"':
-		s.string()
+		if s.peek() == '"' && s.peekNext() == '"' {
+			s.advance()
+			s.advance()
+			s.tripleQuotedString()
+		} else {
+			s.string(stringLiteral{})
+		}
+	case '`':
+		s.rawString()
 	default:
 		if s.isDigit(c) {
 			s.number()
 		} else if s.isAlpha(c) {
 			s.identifier()
 		} else {
-			scannerError(s.line, "unexpected character")
+			s.reportErrorAt(s.line, s.column(s.start), "unexpected character")
 		}
 	}
 }
@@ -121,7 +235,52 @@ func (s *Scanner) advance() rune {
 
 func (s *Scanner) addToken(typ TokenType, literal any) {
 	text := string([]rune(s.source)[s.start:s.current])
-	s.tokens = append(s.tokens, *NewToken(typ, text, literal, s.line))
+	token := NewToken(typ, text, literal, s.line)
+	token.Column = s.column(s.start)
+	if len(s.docs) > 0 {
+		token.Doc = strings.Join(s.docs, "\n")
+		s.docs = nil
+	}
+	s.tokens = append(s.tokens, *token)
+}
+
+// lineComment は"//"から行末までのコメントを読み飛ばす.
+// "///"で始まるドキュメントコメントは,次のトークンに付けるために本文を保存する.
+// "////"のように'/'が4つ以上続く場合は通常のコメントとして扱う.
+func (s *Scanner) lineComment() {
+	isDoc := s.peek() == '/' && s.peekNext() != '/'
+	for s.peek() != '\n' && !s.isAtEnd() {
+		s.advance()
+	}
+	if isDoc {
+		text := string([]rune(s.source)[s.start+3 : s.current])
+		text = strings.TrimSuffix(strings.TrimPrefix(text, " "), "\r")
+		s.docs = append(s.docs, text)
+	}
+}
+
+// blockComment は"/*"から対応する"*/"までのコメントを読み飛ばす.コメントは入れ子にできる.
+func (s *Scanner) blockComment() {
+	line := s.line
+	depth := 1
+	for depth > 0 {
+		if s.isAtEnd() {
+			s.reportError(line, "Unterminated comment.")
+			return
+		}
+		switch {
+		case s.peek() == '/' && s.peekNext() == '*':
+			s.current += 2
+			depth++
+		case s.peek() == '*' && s.peekNext() == '/':
+			s.current += 2
+			depth--
+		default:
+			if s.advance() == '\n' {
+				s.line++
+			}
+		}
+	}
 }
 
 func (s *Scanner) match(expected rune) bool {
@@ -164,8 +323,135 @@ func (s Scanner) isAlphaNumeric(c rune) bool {
 	return s.isAlpha(c) || s.isDigit(c)
 }
 
-func (s *Scanner) string() {
-	for s.peek() != '"' && !s.isAtEnd() {
+// string は文字列リテラルをスキャンする.開始の'"'か,補間式を閉じる'}'の直後から呼ばれる.
+// "${"が現れた場合はそこまでをINTERPOLATIONとして追加し,補間される式のスキャンに戻る.
+// エスケープシーケンスを解釈し,複数行の文字列では各行のインデントを取り除く.
+func (s *Scanner) string(literal stringLiteral) {
+	var builder strings.Builder
+	// blankLine は最後の改行以降に空白しか書き込んでいないかどうか.lineStartはその改行の位置.
+	blankLine := false
+	lineStart := 0
+
+	for {
+		if s.isAtEnd() {
+			s.reportError(s.line, "Unterminated string.")
+			return
+		}
+
+		c := s.peek()
+		if !literal.triple && c == '"' {
+			s.advance()
+			break
+		}
+		if literal.triple && s.isTripleQuote(s.current) {
+			s.current += 3
+			// 閉じる"""だけの行は,直前の改行ごと取り除く.
+			if blankLine {
+				value := builder.String()
+				builder.Reset()
+				builder.WriteString(value[:lineStart])
+			}
+			break
+		}
+		if c == '$' && s.peekNext() == '{' {
+			s.advance()
+			s.advance()
+			s.addToken(INTERPOLATION, builder.String())
+			s.interpolations = append(s.interpolations, literal)
+			return
+		}
+		if c == '\\' {
+			s.escape(&builder)
+			blankLine = false
+			continue
+		}
+
+		s.advance()
+		if c == '\n' {
+			s.line++
+			lineStart = builder.Len()
+			builder.WriteRune(c)
+			if literal.triple {
+				blankLine = true
+				s.skipIndent(literal.indent)
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\r' {
+			blankLine = false
+		}
+		builder.WriteRune(c)
+	}
+
+	s.addToken(STRING, builder.String())
+}
+
+// tripleQuotedString は"""で囲まれた複数行の文字列リテラルをスキャンする.
+// 開始の"""の直後の改行と,閉じる"""だけの行は文字列に含めない.
+// 空白だけの行を除く各行と閉じる"""の行に共通するインデントを,すべての行から取り除く.
+func (s *Scanner) tripleQuotedString() {
+	literal := stringLiteral{triple: true, indent: s.tripleQuoteIndent()}
+	if s.peek() == '\r' && s.peekNext() == '\n' {
+		s.advance()
+	}
+	if s.peek() == '\n' {
+		s.advance()
+		s.line++
+		s.skipIndent(literal.indent)
+	}
+	s.string(literal)
+}
+
+// tripleQuoteIndent は閉じる"""までを先読みして,各行から取り除くインデントの幅を求める.
+// 補間式の中に"""が現れる場合は正しく求められない.
+func (s *Scanner) tripleQuoteIndent() int {
+	runes := []rune(s.source)
+	end := s.current
+	for end < len(runes) && !s.isTripleQuote(end) {
+		if runes[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	lines := strings.Split(string(runes[s.current:end]), "\n")
+	indent := -1
+	// 1行目は開始の"""と同じ行なので数えない.
+	for i, line := range lines[1:] {
+		content := strings.TrimLeft(line, " \t")
+		width := utf8.RuneCountInString(line) - utf8.RuneCountInString(content)
+		isClosingLine := i == len(lines)-2
+		if strings.TrimSpace(content) == "" && !isClosingLine {
+			continue
+		}
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+	if indent < 0 {
+		return 0
+	}
+	return indent
+}
+
+func (s *Scanner) isTripleQuote(position int) bool {
+	runes := []rune(s.source)
+	return position+2 < len(runes) && runes[position] == '"' && runes[position+1] == '"' && runes[position+2] == '"'
+}
+
+// skipIndent は行頭の空白を最大でwidth文字読み飛ばす.
+func (s *Scanner) skipIndent(width int) {
+	for i := 0; i < width && (s.peek() == ' ' || s.peek() == '\t'); i++ {
+		s.advance()
+	}
+}
+
+// rawString は'`'で囲まれた文字列リテラルをスキャンする.エスケープシーケンスや補間は解釈しない.
+func (s *Scanner) rawString() {
+	for s.peek() != '`' && !s.isAtEnd() {
 		if s.peek() == '\n' {
 			s.line++
 		}
@@ -173,7 +459,7 @@ func (s *Scanner) string() {
 	}
 
 	if s.isAtEnd() {
-		scannerError(s.line, "Unterminated string.")
+		s.reportError(s.line, "Unterminated string.")
 		return
 	}
 	s.advance()
@@ -182,17 +468,88 @@ func (s *Scanner) string() {
 	s.addToken(STRING, value)
 }
 
+// escapes は'\'に続く1文字のエスケープシーケンスと,それが表す文字.
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
+	'$':  '$',
+	'`':  '`',
+}
+
+// escape は'\'から始まるエスケープシーケンスを解釈してbuilderに書き込む.
+// 不正なエスケープシーケンスは,その'\'の位置を添えて報告する.
+func (s *Scanner) escape(builder *strings.Builder) {
+	line, column := s.line, s.column(s.current)
+	s.advance()
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	if r, ok := escapes[c]; ok {
+		builder.WriteRune(r)
+		return
+	}
+	if c != 'u' {
+		s.reportErrorAt(line, column, "Invalid escape sequence '\\"+string(c)+"'.")
+		return
+	}
+
+	// \u{1F600}の形で1から6桁の16進数のコードポイントを書く.
+	if !s.match('{') {
+		s.reportErrorAt(line, column, "Expect '{' after '\\u'.")
+		return
+	}
+	digits := s.current
+	for s.isHexDigit(s.peek()) {
+		s.advance()
+	}
+	hex := string([]rune(s.source)[digits:s.current])
+	if !s.match('}') || len(hex) == 0 || len(hex) > 6 {
+		s.reportErrorAt(line, column, "Invalid Unicode escape sequence.")
+		return
+	}
+	code, _ := strconv.ParseInt(hex, 16, 32)
+	if code > utf8.MaxRune || (code >= 0xD800 && code <= 0xDFFF) {
+		s.reportErrorAt(line, column, "Invalid Unicode code point '"+hex+"'.")
+		return
+	}
+	builder.WriteRune(rune(code))
+}
+
+func (s Scanner) isHexDigit(c rune) bool {
+	return s.isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// column はsource中の位置positionが行の何文字目か(1から数える)を返す.
+func (s Scanner) column(position int) int {
+	runes := []rune(s.source)
+	column := 1
+	for i := position - 1; i >= 0 && runes[i] != '\n'; i-- {
+		column++
+	}
+	return column
+}
+
 func (s *Scanner) number() {
 	for s.isDigit(s.peek()) {
 		s.advance()
 	}
 
-	if s.peek() == '.' && s.isDigit(s.peekNext()) {
-		s.advance()
+	// 小数点の無いリテラルは整数になる.
+	if s.peek() != '.' || !s.isDigit(s.peekNext()) {
+		s.addToken(NUMBER, parseInteger(string([]rune(s.source)[s.start:s.current])))
+		return
+	}
+	s.advance()
 
-		for s.isDigit(s.peek()) {
-			s.advance()
-		}
+	for s.isDigit(s.peek()) {
+		s.advance()
 	}
 
 	num, _ := strconv.ParseFloat(string([]rune(s.source)[s.start:s.current]), 64)
@@ -211,3 +568,18 @@ func (s *Scanner) identifier() {
 	}
 	s.addToken(typ, nil)
 }
+
+func (s *Scanner) reportError(line int, message string) {
+	s.diagnostics.Report(Diagnostic{Phase: SCAN_PHASE, Line: line, Message: message})
+}
+
+// reportErrorAt は行の中の位置(1から数える列)を添えてスキャンのエラーを報告する.
+func (s *Scanner) reportErrorAt(line, column int, message string) {
+	s.diagnostics.Report(Diagnostic{
+		Phase:   SCAN_PHASE,
+		Line:    line,
+		Column:  column,
+		Where:   fmt.Sprintf(" at column %d", column),
+		Message: message,
+	})
+}
@@ -8,6 +8,10 @@ type Token struct {
 	Lexeme  string
 	Literal any
 	Line    int
+	// Column はトークンの先頭が行の何文字目か(1から数える).スキャンしたトークンでなければ0.
+	Column int
+	// Doc はトークンの直前に書かれた///のドキュメントコメント.複数行の場合は改行でつなぐ.
+	Doc string
 }
 
 // NewToken はTokenのコンストラクタ.
@@ -13,13 +13,21 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
 	COMMA
+	COLON
 	DOT
 	MINUS
 	PLUS
 	SEMICOLON
 	SLASH
 	STAR
+	PERCENT
+	AMPERSAND
+	PIPE
+	CARET
+	QUESTION
 
 	// 記号1個または2個によるトークン
 	BANG
@@ -30,29 +38,59 @@ const (
 	GREATER_EQUAL
 	LESS
 	LESS_EQUAL
+	ARROW
+	STAR_STAR
+	TILDE
+	TILDE_SLASH
+	LESS_LESS
+	GREATER_GREATER
+	QUESTION_QUESTION
+	QUESTION_DOT
+	DOT_DOT_DOT
+	DOT_DOT
+	PLUS_EQUAL
+	MINUS_EQUAL
+	STAR_EQUAL
+	SLASH_EQUAL
+	PLUS_PLUS
+	MINUS_MINUS
 
 	// リテラル
 	IDENTIFIER
 	STRING
+	// 文字列補間で${の直前までの文字列.補間される式のトークンが後に続く.
+	INTERPOLATION
 	NUMBER
 
 	// キーワード
 	AND
+	BREAK
+	CASE
+	CATCH
 	CLASS
+	CONST
+	CONTINUE
 	ELSE
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IMPORT
+	IN
+	MATCH
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
 	TRUE
+	TRY
 	VAR
 	WHILE
+	YIELD
 
 	EOF
 )
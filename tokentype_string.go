@@ -12,46 +12,83 @@ func _() {
 	_ = x[RIGHT_PAREN-2]
 	_ = x[LEFT_BRACE-3]
 	_ = x[RIGHT_BRACE-4]
-	_ = x[COMMA-5]
-	_ = x[DOT-6]
-	_ = x[MINUS-7]
-	_ = x[PLUS-8]
-	_ = x[SEMICOLON-9]
-	_ = x[SLASH-10]
-	_ = x[STAR-11]
-	_ = x[BANG-12]
-	_ = x[BANG_EQUAL-13]
-	_ = x[EQUAL-14]
-	_ = x[EQUAL_EQUAL-15]
-	_ = x[GREATER-16]
-	_ = x[GREATER_EQUAL-17]
-	_ = x[LESS-18]
-	_ = x[LESS_EQUAL-19]
-	_ = x[IDENTIFIER-20]
-	_ = x[STRING-21]
-	_ = x[NUMBER-22]
-	_ = x[AND-23]
-	_ = x[CLASS-24]
-	_ = x[ELSE-25]
-	_ = x[FALSE-26]
-	_ = x[FUN-27]
-	_ = x[FOR-28]
-	_ = x[IF-29]
-	_ = x[NIL-30]
-	_ = x[OR-31]
-	_ = x[PRINT-32]
-	_ = x[RETURN-33]
-	_ = x[SUPER-34]
-	_ = x[THIS-35]
-	_ = x[TRUE-36]
-	_ = x[VAR-37]
-	_ = x[WHILE-38]
-	_ = x[EOF-39]
+	_ = x[LEFT_BRACKET-5]
+	_ = x[RIGHT_BRACKET-6]
+	_ = x[COMMA-7]
+	_ = x[COLON-8]
+	_ = x[DOT-9]
+	_ = x[MINUS-10]
+	_ = x[PLUS-11]
+	_ = x[SEMICOLON-12]
+	_ = x[SLASH-13]
+	_ = x[STAR-14]
+	_ = x[PERCENT-15]
+	_ = x[AMPERSAND-16]
+	_ = x[PIPE-17]
+	_ = x[CARET-18]
+	_ = x[QUESTION-19]
+	_ = x[BANG-20]
+	_ = x[BANG_EQUAL-21]
+	_ = x[EQUAL-22]
+	_ = x[EQUAL_EQUAL-23]
+	_ = x[GREATER-24]
+	_ = x[GREATER_EQUAL-25]
+	_ = x[LESS-26]
+	_ = x[LESS_EQUAL-27]
+	_ = x[ARROW-28]
+	_ = x[STAR_STAR-29]
+	_ = x[TILDE-30]
+	_ = x[TILDE_SLASH-31]
+	_ = x[LESS_LESS-32]
+	_ = x[GREATER_GREATER-33]
+	_ = x[QUESTION_QUESTION-34]
+	_ = x[QUESTION_DOT-35]
+	_ = x[DOT_DOT_DOT-36]
+	_ = x[DOT_DOT-37]
+	_ = x[PLUS_EQUAL-38]
+	_ = x[MINUS_EQUAL-39]
+	_ = x[STAR_EQUAL-40]
+	_ = x[SLASH_EQUAL-41]
+	_ = x[PLUS_PLUS-42]
+	_ = x[MINUS_MINUS-43]
+	_ = x[IDENTIFIER-44]
+	_ = x[STRING-45]
+	_ = x[INTERPOLATION-46]
+	_ = x[NUMBER-47]
+	_ = x[AND-48]
+	_ = x[BREAK-49]
+	_ = x[CASE-50]
+	_ = x[CATCH-51]
+	_ = x[CLASS-52]
+	_ = x[CONST-53]
+	_ = x[CONTINUE-54]
+	_ = x[ELSE-55]
+	_ = x[FALSE-56]
+	_ = x[FINALLY-57]
+	_ = x[FUN-58]
+	_ = x[FOR-59]
+	_ = x[IF-60]
+	_ = x[IMPORT-61]
+	_ = x[IN-62]
+	_ = x[MATCH-63]
+	_ = x[NIL-64]
+	_ = x[OR-65]
+	_ = x[PRINT-66]
+	_ = x[RETURN-67]
+	_ = x[SUPER-68]
+	_ = x[THIS-69]
+	_ = x[THROW-70]
+	_ = x[TRUE-71]
+	_ = x[TRY-72]
+	_ = x[VAR-73]
+	_ = x[WHILE-74]
+	_ = x[YIELD-75]
+	_ = x[EOF-76]
 }
 
-const _TokenType_name = "LEFT_PARENRIGHT_PARENLEFT_BRACERIGHT_BRACECOMMADOTMINUSPLUSSEMICOLONSLASHSTARBANGBANG_EQUALEQUALEQUAL_EQUALGREATERGREATER_EQUALLESSLESS_EQUALIDENTIFIERSTRINGNUMBERANDCLASSELSEFALSEFUNFORIFNILORPRINTRETURNSUPERTHISTRUEVARWHILEEOF"
+const _TokenType_name = "LEFT_PARENRIGHT_PARENLEFT_BRACERIGHT_BRACELEFT_BRACKETRIGHT_BRACKETCOMMACOLONDOTMINUSPLUSSEMICOLONSLASHSTARPERCENTAMPERSANDPIPECARETQUESTIONBANGBANG_EQUALEQUALEQUAL_EQUALGREATERGREATER_EQUALLESSLESS_EQUALARROWSTAR_STARTILDETILDE_SLASHLESS_LESSGREATER_GREATERQUESTION_QUESTIONQUESTION_DOTDOT_DOT_DOTDOT_DOTPLUS_EQUALMINUS_EQUALSTAR_EQUALSLASH_EQUALPLUS_PLUSMINUS_MINUSIDENTIFIERSTRINGINTERPOLATIONNUMBERANDBREAKCASECATCHCLASSCONSTCONTINUEELSEFALSEFINALLYFUNFORIFIMPORTINMATCHNILORPRINTRETURNSUPERTHISTHROWTRUETRYVARWHILEYIELDEOF"
 
-var _TokenType_index = [...]uint8{0, 10, 21, 31, 42, 47, 50, 55, 59, 68, 73, 77, 81, 91, 96, 107, 114, 127, 131, 141, 151, 157, 163, 166, 171, 175, 180, 183, 186, 188, 191, 193, 198, 204, 209, 213, 217, 220, 225, 228}
+var _TokenType_index = [...]uint16{0, 10, 21, 31, 42, 54, 67, 72, 77, 80, 85, 89, 98, 103, 107, 114, 123, 127, 132, 140, 144, 154, 159, 170, 177, 190, 194, 204, 209, 218, 223, 234, 243, 258, 275, 287, 298, 305, 315, 326, 336, 347, 356, 367, 377, 383, 396, 402, 405, 410, 414, 419, 424, 429, 437, 441, 446, 453, 456, 459, 461, 467, 469, 474, 477, 479, 484, 490, 495, 499, 504, 508, 511, 514, 519, 524, 527}
 
 func (i TokenType) String() string {
 	i -= 1